@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/govm-net/govm/approval"
+	"github.com/govm-net/govm/conf"
+)
+
+// useConsole whether the identifying code of console is used, the stdin is read if true
+func useConsole(c conf.TConfig) bool {
+	if len(c.Approval.Methods) == 0 {
+		return c.IdentifyingCode || c.SafeEnvironment
+	}
+	for _, m := range c.Approval.Methods {
+		if m == "console" {
+			return true
+		}
+	}
+	return false
+}
+
+// newApprover new approver by conf.json, it is new for every request, so the reloaded configure takes effect
+func newApprover(c conf.TConfig) (approval.Approver, error) {
+	methods := c.Approval.Methods
+	if len(methods) == 0 {
+		if !c.IdentifyingCode && !c.SafeEnvironment {
+			return approval.Reject{Reason: "approval closed, set identifying_code or approval.methods"}, nil
+		}
+		methods = []string{"console"}
+	}
+	var out approval.Approvers
+	for _, m := range methods {
+		switch m {
+		case "console":
+			out = append(out, approval.NewConsole(inputString))
+		case "policy":
+			out = append(out, approval.NewPolicy(c.Spending.DailyLimit))
+		case "webhook":
+			out = append(out, approval.NewWebhook(c.Approval.WebhookURL, c.Approval.WebhookTimeout))
+		case "totp":
+			t, err := approval.NewTOTP(c.Approval.TOTPSecret)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, t)
+		default:
+			return nil, fmt.Errorf("unknown method of approval:%s", m)
+		}
+	}
+	return out, nil
+}
+
+// identifyBeforeTransaction approve the transaction(and sign) by the wallet of node before it is signed,
+// the cost and energy of req must be the final values of the transaction.
+// it is checked by the spending policy first, then the approvers. the code of totp is the header X-Approval-Code
+func identifyBeforeTransaction(r *http.Request, req approval.Request) error {
+	c := conf.GetConf()
+	a := approval.Approvers{spendingPolicy{c.Spending}}
+	// the coins of devnet have no value
+	if !c.Devnet {
+		other, err := newApprover(c)
+		if err != nil {
+			return err
+		}
+		a = append(a, other)
+	}
+	req.Caller = getCaller(r).Name
+	req.Code = r.Header.Get("X-Approval-Code")
+	err := a.Approve(req)
+	if err != nil {
+		stat.Add("approval_rejected", 1)
+		apiLog.Warn("transaction not approved", "ops", req.Ops, "chain", req.Chain, "from", req.From,
+			"caller", req.Caller, "error", err)
+		return err
+	}
+	apiLog.Info("transaction approved", "ops", req.Ops, "chain", req.Chain, "from", req.From,
+		"caller", req.Caller, "cost", req.Cost)
+	return nil
+}
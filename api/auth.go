@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/govm-net/govm/conf"
+)
+
+// the scopes of api key, the higher scope includes the lower scopes
+const (
+	ScopeRead  = "read"
+	ScopeSign  = "sign"
+	ScopeAdmin = "admin"
+)
+
+var scopeLevels = map[string]int{ScopeRead: 1, ScopeSign: 2, ScopeAdmin: 3}
+
+// routeScopes the scope of the routes, default: read for GET, admin for others
+var routeScopes = map[string]string{
+	"TransactionSimulatePost":  ScopeRead,
+	"TransactionAppEnergyPost": ScopeRead,
+	"CryptoCheck":              ScopeRead,
+	"JSONRPC":                  ScopeRead,
+	"TransactionNew":           ScopeSign,
+	"TransactionRawPost":       ScopeSign,
+	"TransactionMovePost":      ScopeSign,
+	"TransactionTransferPost":  ScopeSign,
+	"TransactionMinerPost":     ScopeSign,
+	"TransactionNewAppPost":    ScopeSign,
+	"TransactionRunAppPost":    ScopeSign,
+	"TransactionAppLifePost":   ScopeSign,
+	"TransactionAdminPost":     ScopeSign,
+	"CryptoSign":               ScopeSign,
+	"ChainNew":                 ScopeSign,
+}
+
+// rpcScopes the scope of the methods of JSON-RPC, default: read
+var rpcScopes = map[string]string{
+	"govm_sendRawTransaction": ScopeSign,
+	"govm_setLogLevel":        ScopeAdmin,
+}
+
+// authRequired the routes need api key even if auth is disabled(no api key in conf.json)
+var authRequired = map[string]bool{
+	"ConfigReloadPost": true,
+}
+
+// anonymous the caller when auth is disabled, all apis are open as before
+var anonymous = conf.APIKey{Name: "anonymous", Scopes: []string{ScopeAdmin}}
+
+type callerKey struct{}
+
+func routeScope(name, method string) string {
+	if s, ok := routeScopes[name]; ok {
+		return s
+	}
+	if method == http.MethodGet {
+		return ScopeRead
+	}
+	return ScopeAdmin
+}
+
+func rpcScope(method string) string {
+	if s, ok := rpcScopes[method]; ok {
+		return s
+	}
+	return ScopeRead
+}
+
+// hasScope check whether the key has the scope
+func hasScope(key conf.APIKey, scope string) bool {
+	for _, s := range key.Scopes {
+		if scopeLevels[s] >= scopeLevels[scope] {
+			return true
+		}
+	}
+	return false
+}
+
+func authEnabled(c conf.TConfig) bool {
+	return len(c.APIKeys) > 0 || c.AdminToken != ""
+}
+
+// requestKey get the api key from "Authorization: Bearer <key>" or "X-API-Key: <key>"
+func requestKey(r *http.Request) string {
+	const prefix = "Bearer "
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, prefix) {
+		return strings.TrimSpace(auth[len(prefix):])
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// findKey find the api key, the admin_token is the key with admin scope
+func findKey(c conf.TConfig, key string) (conf.APIKey, bool) {
+	if key == "" {
+		return conf.APIKey{}, false
+	}
+	var out conf.APIKey
+	var found bool
+	for _, it := range c.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(it.Key)) == 1 {
+			out, found = it, true
+		}
+	}
+	if c.AdminToken != "" && subtle.ConstantTimeCompare([]byte(key), []byte(c.AdminToken)) == 1 {
+		out, found = conf.APIKey{Name: "admin_token", Scopes: []string{ScopeAdmin}}, true
+	}
+	return out, found
+}
+
+// getCaller get the api key of the request, it is set by Auth
+func getCaller(r *http.Request) conf.APIKey {
+	if k, ok := r.Context().Value(callerKey{}).(conf.APIKey); ok {
+		return k
+	}
+	return conf.APIKey{}
+}
+
+// AuditRecord the record of audit log
+type AuditRecord struct {
+	Time      string `json:"time"`
+	Caller    string `json:"caller"`
+	Remote    string `json:"remote"`
+	Method    string `json:"method"`
+	URI       string `json:"uri"`
+	Route     string `json:"route"`
+	Scope     string `json:"scope"`
+	RPCMethod string `json:"rpc_method,omitempty"`
+	Status    int    `json:"status"`
+	Error     string `json:"error,omitempty"`
+	Duration  string `json:"duration,omitempty"`
+}
+
+var (
+	auditMu  sync.Mutex
+	auditOut io.Writer = ioutil.Discard
+)
+
+// SetAuditOutput set the output of audit log, discard if w is nil
+func SetAuditOutput(w io.Writer) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if w == nil {
+		w = ioutil.Discard
+	}
+	auditOut = w
+}
+
+func audit(r *http.Request, rec AuditRecord) {
+	rec.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	rec.Remote = r.RemoteAddr
+	rec.Method = r.Method
+	rec.URI = r.RequestURI
+	if rec.Caller == "" {
+		rec.Caller = getCaller(r).Name
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	stat.Add("audit", 1)
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	auditOut.Write(append(data, '\n'))
+}
+
+// statusWriter record the status of response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Auth check the api key and the scope of the request,
+// the state-changing requests(scope is sign or admin) are written to the audit log, include the rejected requests
+func Auth(inner http.Handler, name, scope string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := AuditRecord{Route: name, Scope: scope}
+		if scope != ScopeRead {
+			sw := &statusWriter{ResponseWriter: w}
+			w = sw
+			defer func() {
+				rec.Status = sw.status
+				if rec.Status == 0 {
+					rec.Status = http.StatusOK
+				}
+				rec.Duration = time.Since(start).String()
+				audit(r, rec)
+			}()
+		}
+		c := conf.GetConf()
+		key := anonymous
+		if !authEnabled(c) {
+			rec.Caller = key.Name
+			if authRequired[name] {
+				rec.Error = "auth disabled"
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprintln(w, "the api is disabled, set api_keys or admin_token of conf.json")
+				return
+			}
+		} else {
+			var ok bool
+			key, ok = findKey(c, requestKey(r))
+			if !ok {
+				stat.Add("unauthorized", 1)
+				rec.Error = "unauthorized"
+				apiLog.Warn("unauthorized request", "uri", r.RequestURI, "name", name, "remote", r.RemoteAddr)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprintln(w, "unauthorized")
+				return
+			}
+			rec.Caller = key.Name
+			if !hasScope(key, scope) {
+				stat.Add("forbidden", 1)
+				rec.Error = "need scope " + scope
+				apiLog.Warn("forbidden request", "uri", r.RequestURI, "name", name, "caller", key.Name, "scope", scope)
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprintln(w, "forbidden, need scope:", scope)
+				return
+			}
+		}
+		rec.Caller = key.Name
+		inner.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, key)))
+	})
+}
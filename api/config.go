@@ -0,0 +1,22 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/govm-net/govm/conf"
+)
+
+// ConfigReloadPost reload conf.json, it is the same as SIGHUP.
+// the configure is validated before applied, return the changed fields.
+// it needs the api key with admin scope, see Auth
+func ConfigReloadPost(w http.ResponseWriter, r *http.Request) {
+	rst, err := conf.Reload()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "fail to reload configure,", err)
+		return
+	}
+	apiLog.Info("reload configure", "changed", rst.Changed, "need_restart", rst.NeedRestart)
+	writeJSON(w, rst)
+}
@@ -12,17 +12,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"math/rand"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/govm-net/govm/approval"
 	"github.com/govm-net/govm/conf"
 	core "github.com/govm-net/govm/core"
 	"github.com/govm-net/govm/event"
 	"github.com/govm-net/govm/handler"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/messages"
 	"github.com/govm-net/govm/runtime"
 	"github.com/govm-net/govm/wallet"
@@ -33,7 +34,7 @@ var inputString chan string
 func init() {
 	rand.Seed(time.Now().UnixNano())
 	inputString = make(chan string, 1)
-	if !conf.GetConf().IdentifyingCode {
+	if !useConsole(conf.GetConf()) {
 		return
 	}
 	go func() {
@@ -52,38 +53,16 @@ func init() {
 	}()
 }
 
-// identifying code,before new transaction,user need input it.
-func identifyBeforeTransaction(msg ...interface{}) error {
-	c := conf.GetConf()
-	if !c.IdentifyingCode && !c.SafeEnvironment {
-		return fmt.Errorf("not support, IdentifyingCode closed")
-	}
-	//clean inputString if exist
-	select {
-	case <-inputString:
-		log.Println("clean old inputString")
-	default:
+// getWallet get the wallet of from(name or address of keystore, default the wallet of node),
+// write the error response if fail
+func getWallet(w http.ResponseWriter, from string) (wallet.TWallet, bool) {
+	acc, err := conf.GetWallet(from)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "error from,", err)
+		return acc, false
 	}
-	r := rand.Int63()
-	str := fmt.Sprintf("%06d", r%1000000)
-	if len(msg) > 0 {
-		fmt.Println("Identify:", msg)
-	}
-	fmt.Println("Input Identifying Code:")
-	fmt.Println(str)
-	var in string
-	timeout := time.After(time.Second * 30)
-	select {
-	case <-timeout:
-		fmt.Println("identify:input timeout(30second)")
-		return fmt.Errorf("identify:timeout")
-	case in = <-inputString:
-	}
-	if in != str {
-		fmt.Printf("error identifying code,hope:%s,get:%s\n", str, in)
-		return fmt.Errorf("error indentifying code")
-	}
-	return nil
+	return acc, true
 }
 
 // Account account
@@ -143,12 +122,7 @@ func TransactionNew(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("error chain"))
 		return
 	}
-	key := runtime.GetHash(data)
-	msg := new(messages.NewTransaction)
-	msg.Chain = chain
-	msg.Key = key
-	msg.Data = data
-	err = event.Send(msg)
+	_, err = newTransaction(chain, data)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
@@ -157,12 +131,23 @@ func TransactionNew(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// newTransaction send the signed transaction to handler, return the key of transaction
+func newTransaction(chain uint64, data []byte) ([]byte, error) {
+	key := runtime.GetHash(data)
+	msg := new(messages.NewTransaction)
+	msg.Chain = chain
+	msg.Key = key
+	msg.Data = data
+	return key, event.Send(msg)
+}
+
 // TransMoveInfo move info
 type TransMoveInfo struct {
 	DstChain uint64 `json:"dst_chain,omitempty"`
 	Cost     uint64 `json:"cost,omitempty"`
 	Energy   uint64 `json:"energy,omitempty"`
 	TransKey string `json:"trans_key,omitempty"`
+	From     string `json:"from,omitempty"`
 }
 
 // TransactionMovePost move cost to other chain
@@ -188,31 +173,35 @@ func TransactionMovePost(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "fail to Unmarshal body of request,", err)
 		return
 	}
-	c := conf.GetConf()
-	coin := core.GetUserCoin(chain, c.WalletAddr)
+	acc, ok := getWallet(w, info.From)
+	if !ok {
+		return
+	}
+	coin := core.GetUserCoin(chain, acc.Address)
 	if coin < info.Cost {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "not enough cost.have:%d,hope:%d\n", coin, info.Cost)
 		return
 	}
-	err = identifyBeforeTransaction("Move:", chain, string(data))
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
-		return
-	}
 	cAddr := core.Address{}
-	runtime.Decode(c.WalletAddr, &cAddr)
+	runtime.Decode(acc.Address, &cAddr)
 	trans := core.NewTransaction(chain, cAddr)
 	trans.CreateMove(info.DstChain, info.Cost)
 	if info.Energy > trans.Energy {
 		trans.Energy = info.Energy
 	}
+	req := approval.Request{Ops: approval.OpsMove, Chain: chain, From: hex.EncodeToString(acc.Address), Cost: info.Cost, Energy: trans.Energy, Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
+		return
+	}
 	td := trans.GetSignData()
-	sign := wallet.Sign(c.PrivateKey, td)
-	if len(c.SignPrefix) > 0 {
-		s := make([]byte, len(c.SignPrefix))
-		copy(s, c.SignPrefix)
+	sign := wallet.Sign(acc.Key, td)
+	if len(acc.SignPrefix) > 0 {
+		s := make([]byte, len(acc.SignPrefix))
+		copy(s, acc.SignPrefix)
 		sign = append(s, sign...)
 	}
 	trans.SetSign(sign)
@@ -225,6 +214,7 @@ func TransactionMovePost(w http.ResponseWriter, r *http.Request) {
 	msg.Data = td
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -245,6 +235,7 @@ type TransferInfo struct {
 	Cost     uint64 `json:"cost,omitempty"`
 	Energy   uint64 `json:"energy,omitempty"`
 	TransKey string `json:"trans_key,omitempty"`
+	From     string `json:"from,omitempty"`
 }
 
 // TransactionTransferPost transfer
@@ -286,34 +277,38 @@ func TransactionTransferPost(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "error length of peer address,", info.Peer)
 		return
 	}
-	c := conf.GetConf()
-	coin := core.GetUserCoin(chain, c.WalletAddr)
+	acc, ok := getWallet(w, info.From)
+	if !ok {
+		return
+	}
+	coin := core.GetUserCoin(chain, acc.Address)
 	if coin < info.Cost {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "not enough cost.have:%d,hope:%d\n", coin, info.Cost)
 		return
 	}
-	err = identifyBeforeTransaction("Transfer:", chain, string(data))
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
-		return
-	}
 	cAddr := core.Address{}
 	dstAddr := core.Address{}
-	runtime.Decode(c.WalletAddr, &cAddr)
+	runtime.Decode(acc.Address, &cAddr)
 	runtime.Decode(dst, &dstAddr)
-	log.Printf("transfer,from:%x,to:%x,cost:%d\n", cAddr, dstAddr, info.Cost)
+	apiLog.Info("transfer", logger.KeyChain, chain, "from", cAddr, "to", dstAddr, "cost", info.Cost)
 	trans := core.NewTransaction(chain, cAddr)
 	trans.CreateTransfer(dstAddr, info.Cost)
 	if info.Energy > trans.Energy {
 		trans.Energy = info.Energy
 	}
+	req := approval.Request{Ops: approval.OpsTransfer, Chain: chain, From: hex.EncodeToString(acc.Address), Peer: info.Peer, Cost: info.Cost, Energy: trans.Energy, Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
+		return
+	}
 	td := trans.GetSignData()
-	sign := wallet.Sign(c.PrivateKey, td)
-	if len(c.SignPrefix) > 0 {
-		s := make([]byte, len(c.SignPrefix))
-		copy(s, c.SignPrefix)
+	sign := wallet.Sign(acc.Key, td)
+	if len(acc.SignPrefix) > 0 {
+		s := make([]byte, len(acc.SignPrefix))
+		copy(s, acc.SignPrefix)
 		sign = append(s, sign...)
 	}
 	trans.SetSign(sign)
@@ -326,6 +321,7 @@ func TransactionTransferPost(w http.ResponseWriter, r *http.Request) {
 	msg.Data = td
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -347,6 +343,7 @@ type Miner struct {
 	Energy      uint64 `json:"energy,omitempty"`
 	Miner       string `json:"miner,omitempty"`
 	TransKey    string `json:"trans_key,omitempty"`
+	From        string `json:"from,omitempty"`
 }
 
 // TransactionMinerPost register miner
@@ -372,21 +369,18 @@ func TransactionMinerPost(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "fail to Unmarshal body of request,", err)
 		return
 	}
-	c := conf.GetConf()
-	coin := core.GetUserCoin(chain, c.WalletAddr)
+	acc, ok := getWallet(w, info.From)
+	if !ok {
+		return
+	}
+	coin := core.GetUserCoin(chain, acc.Address)
 	if coin < info.Cost {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "not enough cost.have:%d,hope:%d\n", coin, info.Cost)
 		return
 	}
-	err = identifyBeforeTransaction("Register miner:", chain, string(data))
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
-		return
-	}
 	cAddr := core.Address{}
-	runtime.Decode(c.WalletAddr, &cAddr)
+	runtime.Decode(acc.Address, &cAddr)
 	trans := core.NewTransaction(chain, cAddr)
 	var peer []byte
 	if info.Miner != "" {
@@ -396,11 +390,18 @@ func TransactionMinerPost(w http.ResponseWriter, r *http.Request) {
 	if info.Energy > trans.Energy {
 		trans.Energy = info.Energy
 	}
+	req := approval.Request{Ops: approval.OpsMiner, Chain: chain, From: hex.EncodeToString(acc.Address), Peer: info.Miner, Cost: info.Cost, Energy: trans.Energy, Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
+		return
+	}
 	td := trans.GetSignData()
-	sign := wallet.Sign(c.PrivateKey, td)
-	if len(c.SignPrefix) > 0 {
-		s := make([]byte, len(c.SignPrefix))
-		copy(s, c.SignPrefix)
+	sign := wallet.Sign(acc.Key, td)
+	if len(acc.SignPrefix) > 0 {
+		s := make([]byte, len(acc.SignPrefix))
+		copy(s, acc.SignPrefix)
 		sign = append(s, sign...)
 	}
 	trans.SetSign(sign)
@@ -413,6 +414,7 @@ func TransactionMinerPost(w http.ResponseWriter, r *http.Request) {
 	msg.Data = td
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -436,6 +438,7 @@ type NewApp struct {
 	EnableImport bool   `json:"enable_import,omitempty"`
 	AppName      string `json:"app_name,omitempty"`
 	TransKey     string `json:"trans_key,omitempty"`
+	From         string `json:"from,omitempty"`
 }
 
 // TransactionNewAppPost new app
@@ -468,45 +471,47 @@ func TransactionNewAppPost(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "fail to Unmarshal body of request,", err)
 		return
 	}
-	c := conf.GetConf()
-	coin := core.GetUserCoin(chain, c.WalletAddr)
+	acc, ok := getWallet(w, info.From)
+	if !ok {
+		return
+	}
+	coin := core.GetUserCoin(chain, acc.Address)
 	if coin < info.Cost {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "not enough cost.have:%d,hope:%d\n", coin, info.Cost)
 		return
 	}
-	err = identifyBeforeTransaction("New APP:", chain, string(data))
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
-		return
-	}
 	var flag uint8
 	if !info.IsPrivate {
 		flag |= core.AppFlagPlublc
-		log.Println("1. flag:", flag)
 	}
 	if info.EnableRun {
 		flag |= core.AppFlagRun
-		log.Println("2. flag:", flag)
 	}
 	if info.EnableImport {
 		flag |= core.AppFlagImport
-		log.Println("3. flag:", flag)
 	}
+	apiLog.Info("new app", logger.KeyChain, chain, "code_path", info.CodePath, "flag", flag)
 	code, ln := core.CreateAppFromSourceCode(info.CodePath, flag)
 	cAddr := core.Address{}
-	runtime.Decode(c.WalletAddr, &cAddr)
+	runtime.Decode(acc.Address, &cAddr)
 	trans := core.NewTransaction(chain, cAddr)
 	trans.CreateNewApp(code, ln)
 	if info.Energy > trans.Energy {
 		trans.Energy = info.Energy
 	}
+	req := approval.Request{Ops: approval.OpsNewApp, Chain: chain, From: hex.EncodeToString(acc.Address), Cost: info.Cost, Energy: trans.Energy, Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
+		return
+	}
 	td := trans.GetSignData()
-	sign := wallet.Sign(c.PrivateKey, td)
-	if len(c.SignPrefix) > 0 {
-		s := make([]byte, len(c.SignPrefix))
-		copy(s, c.SignPrefix)
+	sign := wallet.Sign(acc.Key, td)
+	if len(acc.SignPrefix) > 0 {
+		s := make([]byte, len(acc.SignPrefix))
+		copy(s, acc.SignPrefix)
 		sign = append(s, sign...)
 	}
 	trans.SetSign(sign)
@@ -519,6 +524,7 @@ func TransactionNewAppPost(w http.ResponseWriter, r *http.Request) {
 	msg.Data = td
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -545,6 +551,45 @@ type RunApp struct {
 	Param     string      `json:"param,omitempty"`
 	ParamType string      `json:"param_type,omitempty"`
 	JSONParam interface{} `json:"json_param,omitempty"`
+	From      string      `json:"from,omitempty"`
+	// AutoEnergy set the energy by the estimate of node(with margin), if it is more than Energy
+	AutoEnergy bool `json:"auto_energy,omitempty"`
+}
+
+// decodeRunApp decode the app name and the parameter of app
+func decodeRunApp(info RunApp) (core.Hash, []byte, error) {
+	var err error
+	var param []byte
+	app := core.Hash{}
+	switch info.ParamType {
+	case "json":
+		if len(info.Param) > 0 {
+			param, err = hex.DecodeString(info.Param)
+			if err != nil {
+				return app, nil, fmt.Errorf("error param, hope hex string,%s", err)
+			}
+		}
+		jData, _ := json.Marshal(info.JSONParam)
+		param = append(param, jData...)
+	case "string":
+		param = []byte(info.Param)
+	default:
+		if len(info.Param) > 0 {
+			param, err = hex.DecodeString(info.Param)
+			if err != nil {
+				return app, nil, fmt.Errorf("error param, hope hex string,%s", err)
+			}
+		}
+	}
+	d, err := hex.DecodeString(info.AppName)
+	if err != nil {
+		return app, nil, fmt.Errorf("error AppName, hope hex string,%s", err)
+	}
+	runtime.Decode(d, &app)
+	if app.Empty() {
+		return app, nil, fmt.Errorf("error AppName, fail to decode,%s", info.AppName)
+	}
+	return app, param, nil
 }
 
 // RespOfNewTrans the response of New Transaction
@@ -576,77 +621,54 @@ func TransactionRunAppPost(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "fail to Unmarshal body of request,", err)
 		return
 	}
-	log.Println("run app:", info)
-	var param []byte
-	switch info.ParamType {
-	case "json":
-		if len(info.Param) > 0 {
-			prefix, err := hex.DecodeString(info.Param)
-			if err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				fmt.Fprintln(w, "error param, hope hex string,", err)
-				return
-			}
-			param = prefix
-		}
-		jData, _ := json.Marshal(info.JSONParam)
-		param = append(param, jData...)
-	case "string":
-		param = []byte(info.Param)
-	default:
-		if len(info.Param) > 0 {
-			param, err = hex.DecodeString(info.Param)
-			if err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				fmt.Fprintln(w, "error param, hope hex string,", err)
-				return
-			}
-		}
-	}
-	app := core.Hash{}
-	{
-		d, err := hex.DecodeString(info.AppName)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintln(w, "error AppName, hope hex string,", err)
-			return
-		}
-		runtime.Decode(d, &app)
-	}
-	if (app == core.Hash{}) {
+	apiLog.Info("run app", logger.KeyChain, chain, logger.KeyApp, info.AppName, "cost", info.Cost, "energy", info.Energy)
+	app, param, err := decodeRunApp(info)
+	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintln(w, "error AppName, fail to decode,", info.AppName)
+		fmt.Fprintln(w, err)
 		return
 	}
 
-	c := conf.GetConf()
-	coin := core.GetUserCoin(chain, c.WalletAddr)
+	acc, ok := getWallet(w, info.From)
+	if !ok {
+		return
+	}
+	coin := core.GetUserCoin(chain, acc.Address)
 	if coin < info.Cost {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "not enough cost.have:%d,hope:%d\n", coin, info.Cost)
 		return
 	}
 
-	err = identifyBeforeTransaction("Run APP:", chain, string(data))
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
-		return
-	}
-
 	cAddr := core.Address{}
-	runtime.Decode(c.WalletAddr, &cAddr)
+	runtime.Decode(acc.Address, &cAddr)
 	trans := core.NewTransaction(chain, cAddr)
 	trans.CreateRunApp(app, info.Cost, param)
+	if info.AutoEnergy {
+		est := estimateAppEnergy(chain, *trans, DefEnergyMargin)
+		if est.Error != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "fail to estimate energy,%s", est.Error)
+			return
+		}
+		trans.Energy = est.Energy
+	}
 
 	if info.Energy > trans.Energy {
 		trans.Energy = info.Energy
 	}
+	req := approval.Request{Ops: approval.OpsRunApp, Chain: chain, From: hex.EncodeToString(acc.Address), Peer: info.AppName, Cost: info.Cost, Energy: trans.Energy, Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
+		return
+	}
 	td := trans.GetSignData()
-	sign := wallet.Sign(c.PrivateKey, td)
-	if len(c.SignPrefix) > 0 {
-		s := make([]byte, len(c.SignPrefix))
-		copy(s, c.SignPrefix)
+	sign := wallet.Sign(acc.Key, td)
+	if len(acc.SignPrefix) > 0 {
+		s := make([]byte, len(acc.SignPrefix))
+		copy(s, acc.SignPrefix)
 		sign = append(s, sign...)
 	}
 	trans.SetSign(sign)
@@ -658,6 +680,7 @@ func TransactionRunAppPost(w http.ResponseWriter, r *http.Request) {
 	msg.Data = td
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -676,6 +699,7 @@ type AppLife struct {
 	AppName  string `json:"app_name,omitempty"`
 	Life     uint64 `json:"life,omitempty"`
 	TransKey string `json:"trans_key,omitempty"`
+	From     string `json:"from,omitempty"`
 }
 
 // TransactionAppLifePost update app life
@@ -710,33 +734,37 @@ func TransactionAppLifePost(w http.ResponseWriter, r *http.Request) {
 	}
 	runtime.Decode(d, &app)
 
-	c := conf.GetConf()
-	coin := core.GetUserCoin(chain, c.WalletAddr)
+	acc, ok := getWallet(w, info.From)
+	if !ok {
+		return
+	}
+	coin := core.GetUserCoin(chain, acc.Address)
 	if coin < info.Energy {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "not enough cost.have:%d,hope:%d\n", coin, info.Energy)
 		return
 	}
-	err = identifyBeforeTransaction("Update APP Life:", chain, string(data))
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
-		return
-	}
 
 	cAddr := core.Address{}
-	runtime.Decode(c.WalletAddr, &cAddr)
+	runtime.Decode(acc.Address, &cAddr)
 	trans := core.NewTransaction(chain, cAddr)
 	trans.CreateUpdateAppLife(app, info.Life)
 
 	if info.Energy > trans.Energy {
 		trans.Energy = info.Energy
 	}
+	req := approval.Request{Ops: approval.OpsAppLife, Chain: chain, From: hex.EncodeToString(acc.Address), Peer: info.AppName, Energy: trans.Energy, Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
+		return
+	}
 	td := trans.GetSignData()
-	sign := wallet.Sign(c.PrivateKey, td)
-	if len(c.SignPrefix) > 0 {
-		s := make([]byte, len(c.SignPrefix))
-		copy(s, c.SignPrefix)
+	sign := wallet.Sign(acc.Key, td)
+	if len(acc.SignPrefix) > 0 {
+		s := make([]byte, len(acc.SignPrefix))
+		copy(s, acc.SignPrefix)
 		sign = append(s, sign...)
 	}
 	trans.SetSign(sign)
@@ -748,6 +776,7 @@ func TransactionAppLifePost(w http.ResponseWriter, r *http.Request) {
 	msg.Data = td
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -787,7 +816,7 @@ func TransactionAppInfoGet(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "chain:%d,key:%x", chain, key)
 		return
 	}
-	log.Println("app info:", info)
+	apiLog.Debug("app info", logger.KeyChain, chain, logger.KeyApp, key, "info", info)
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
 	enc := json.NewEncoder(w)
@@ -829,13 +858,28 @@ func TransactionInfoGet(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "chain:%d,key:%x", chain, key)
 		return
 	}
-	trans := core.DecodeTrans(data)
-	if trans == nil {
+	info := getTransInfo(chain, key, data)
+	if info == nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte("error key"))
 		return
 	}
 
+	d, _ := json.Marshal(info.Others)
+	apiLog.Debug("trans info", logger.KeyChain, chain, logger.KeyTrans, key, "others", string(d))
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.Encode(info)
+}
+
+func getTransInfo(chain uint64, key, data []byte) *TransInfo {
+	trans := core.DecodeTrans(data)
+	if trans == nil {
+		return nil
+	}
+
 	info := TransInfo{}
 	info.TransactionHead = trans.TransactionHead
 	info.Key = key
@@ -844,14 +888,7 @@ func TransactionInfoGet(w http.ResponseWriter, r *http.Request) {
 	si["BlockID"] = ti.BlockID
 	info.Others = si
 	info.Size = len(data)
-
-	d, _ := json.Marshal(info.Others)
-	log.Println("trans info:", info.Others, string(d))
-
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	enc := json.NewEncoder(w)
-	enc.Encode(info)
+	return &info
 }
 
 // BlockMinePost mine
@@ -864,16 +901,18 @@ func BlockMinePost(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("error chain"))
 		return
 	}
-	err = identifyBeforeTransaction("Force mine:", chain)
+	req := approval.Request{Ops: approval.OpsMine, Chain: chain}
+	err = identifyBeforeTransaction(r, req)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
 		return
 	}
 	msg := new(messages.Mine)
 	msg.Chain = chain
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -932,13 +971,28 @@ func BlockInfoGet(w http.ResponseWriter, r *http.Request) {
 	} else {
 		key = core.GetTheBlockKey(chain, index)
 	}
-	data := core.ReadBlockData(chain, key)
-	if len(data) == 0 {
+	info := getBlockInfo(chain, key)
+	if info == nil {
 		w.WriteHeader(http.StatusNotFound)
 		fmt.Fprintf(w, "error key.chain:%d,key:%x,index:%d\n", chain, key, index)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.Encode(info)
+}
+
+func getBlockInfo(chain uint64, key []byte) *blockInfo {
+	data := core.ReadBlockData(chain, key)
+	if len(data) == 0 {
+		return nil
+	}
 	block := core.DecodeBlock(data)
+	if block == nil {
+		return nil
+	}
 	info := blockInfo{}
 	info.Time = block.Time
 	info.Previous = hex.EncodeToString(block.Previous[:])
@@ -959,11 +1013,7 @@ func BlockInfoGet(w http.ResponseWriter, r *http.Request) {
 	info.Index = block.Index
 	info.Nonce = block.Nonce
 	info.Key = hex.EncodeToString(block.Key[:])
-
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	enc := json.NewEncoder(w)
-	enc.Encode(info)
+	return &info
 }
 
 // ChainNewInfo info of new chain
@@ -972,6 +1022,7 @@ type ChainNewInfo struct {
 	Cost     uint64 `json:"cost,omitempty"`
 	Energy   uint64 `json:"energy,omitempty"`
 	TransKey string `json:"trans_key,omitempty"`
+	From     string `json:"from,omitempty"`
 }
 
 // ChainNew new chain
@@ -1002,31 +1053,35 @@ func ChainNew(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "error dst chain,", info.DstChain)
 		return
 	}
-	c := conf.GetConf()
-	coin := core.GetUserCoin(chain, c.WalletAddr)
+	acc, ok := getWallet(w, info.From)
+	if !ok {
+		return
+	}
+	coin := core.GetUserCoin(chain, acc.Address)
 	if coin < info.Cost {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "not enough cost.have:%d,hope:%d\n", coin, info.Cost)
 		return
 	}
-	err = identifyBeforeTransaction("New chain:", chain, string(data))
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
-		return
-	}
 	cAddr := core.Address{}
-	runtime.Decode(c.WalletAddr, &cAddr)
+	runtime.Decode(acc.Address, &cAddr)
 	trans := core.NewTransaction(chain, cAddr)
 	trans.CreateNewChain(info.DstChain, info.Cost)
 	if info.Energy > trans.Energy {
 		trans.Energy = info.Energy
 	}
+	req := approval.Request{Ops: approval.OpsNewChain, Chain: chain, From: hex.EncodeToString(acc.Address), Cost: info.Cost, Energy: trans.Energy, Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
+		return
+	}
 	td := trans.GetSignData()
-	sign := wallet.Sign(c.PrivateKey, td)
-	if len(c.SignPrefix) > 0 {
-		s := make([]byte, len(c.SignPrefix))
-		copy(s, c.SignPrefix)
+	sign := wallet.Sign(acc.Key, td)
+	if len(acc.SignPrefix) > 0 {
+		s := make([]byte, len(acc.SignPrefix))
+		copy(s, acc.SignPrefix)
 		sign = append(s, sign...)
 	}
 	trans.SetSign(sign)
@@ -1039,6 +1094,7 @@ func ChainNew(w http.ResponseWriter, r *http.Request) {
 	msg.Data = td
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -1354,10 +1410,11 @@ func CryptoSign(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	err = identifyBeforeTransaction("Sign:", string(data))
+	req := approval.Request{Ops: approval.OpsSign, From: hex.EncodeToString(conf.GetConf().WalletAddr), Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
 		return
 	}
 	c := conf.GetConf()
@@ -1515,6 +1572,7 @@ func MiningBlockGet(w http.ResponseWriter, r *http.Request) {
 type Admin struct {
 	Cost     uint64 `json:"cost,omitempty"`
 	TransKey string `json:"trans_key,omitempty"`
+	From     string `json:"from,omitempty"`
 }
 
 // TransactionAdminPost register admin candidate
@@ -1540,28 +1598,32 @@ func TransactionAdminPost(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "fail to Unmarshal body of request,", err)
 		return
 	}
-	c := conf.GetConf()
-	coin := core.GetUserCoin(chain, c.WalletAddr)
+	acc, ok := getWallet(w, info.From)
+	if !ok {
+		return
+	}
+	coin := core.GetUserCoin(chain, acc.Address)
 	if coin < info.Cost {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "not enough cost.have:%d,hope:%d\n", coin, info.Cost)
 		return
 	}
-	err = identifyBeforeTransaction("Register miner:", chain, string(data))
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Fprintf(w, "identifying code error,%s", err)
-		return
-	}
 	cAddr := core.Address{}
-	runtime.Decode(c.WalletAddr, &cAddr)
+	runtime.Decode(acc.Address, &cAddr)
 	trans := core.NewTransaction(chain, cAddr)
 	trans.RegisterAdmin(info.Cost)
+	req := approval.Request{Ops: approval.OpsAdmin, Chain: chain, From: hex.EncodeToString(acc.Address), Cost: info.Cost, Energy: trans.Energy, Detail: string(data)}
+	err = identifyBeforeTransaction(r, req)
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "not approved,%s", err)
+		return
+	}
 	td := trans.GetSignData()
-	sign := wallet.Sign(c.PrivateKey, td)
-	if len(c.SignPrefix) > 0 {
-		s := make([]byte, len(c.SignPrefix))
-		copy(s, c.SignPrefix)
+	sign := wallet.Sign(acc.Key, td)
+	if len(acc.SignPrefix) > 0 {
+		s := make([]byte, len(acc.SignPrefix))
+		copy(s, acc.SignPrefix)
 		sign = append(s, sign...)
 	}
 	trans.SetSign(sign)
@@ -1574,6 +1636,7 @@ func TransactionAdminPost(w http.ResponseWriter, r *http.Request) {
 	msg.Data = td
 	err = event.Send(msg)
 	if err != nil {
+		releaseSpending(req)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "error:%s", err)
 		return
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/govm-net/govm/handler"
+)
+
+// DevnetMineResult the block produced by devnet
+type DevnetMineResult struct {
+	Chain     uint64 `json:"chain"`
+	Index     uint64 `json:"index"`
+	Key       string `json:"key"`
+	Time      uint64 `json:"time"`
+	HashPower uint64 `json:"hash_power"`
+}
+
+// DevnetMinePost produce a block of devnet at once, it returns after the block is processed
+func DevnetMinePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	rel, err := handler.DevnetMine(chain)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+	out := DevnetMineResult{Chain: chain, Index: rel.Index, Time: rel.Time, HashPower: rel.HashPower}
+	out.Key = fmt.Sprintf("%x", rel.Key)
+	writeJSON(w, out)
+}
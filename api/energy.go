@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/govm-net/govm/conf"
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/runtime"
+)
+
+// DefEnergyMargin the default safety margin(percent) of the estimated energy
+const DefEnergyMargin = 20
+
+// EnergyEstimate the estimate of energy of app call
+type EnergyEstimate struct {
+	Chain uint64 `json:"chain,omitempty"`
+	// Used the energy used by apps
+	Used uint64 `json:"energy_used"`
+	// MinEnergy the minimum energy of transaction, the app can use half of it
+	MinEnergy uint64 `json:"min_energy"`
+	// Energy the energy with margin, it can be used as the energy of transaction
+	Energy uint64 `json:"energy"`
+	Margin uint64 `json:"margin"`
+	Error  string `json:"error,omitempty"`
+}
+
+// EnergyEstimateInfo the app call to estimate energy, the user is the wallet(From) if it is empty
+type EnergyEstimateInfo struct {
+	RunApp
+	User   string `json:"user,omitempty"`
+	Margin uint64 `json:"margin,omitempty"`
+}
+
+// estimateAppEnergy run the app call in test mode, return the energy with margin(percent)
+func estimateAppEnergy(chain uint64, trans core.StTrans, margin uint64) EnergyEstimate {
+	out := EnergyEstimate{Chain: chain, Margin: margin}
+	min, rst := core.EstimateEnergy(chain, trans)
+	out.Used = rst.Used
+	out.Error = rst.Error
+	if out.Error != "" {
+		return out
+	}
+	out.MinEnergy = min
+	out.Energy = min + min*margin/100
+	return out
+}
+
+func estimateEnergy(chain uint64, info EnergyEstimateInfo) (EnergyEstimate, error) {
+	app, param, err := decodeRunApp(info.RunApp)
+	if err != nil {
+		return EnergyEstimate{}, err
+	}
+	user := core.Address{}
+	if info.User != "" {
+		d, err := hex.DecodeString(info.User)
+		if err != nil || len(d) != core.AddressLen {
+			return EnergyEstimate{}, fmt.Errorf("error user,hope hex address:%s", info.User)
+		}
+		runtime.Decode(d, &user)
+	} else {
+		acc, err := conf.GetWallet(info.From)
+		if err != nil {
+			return EnergyEstimate{}, fmt.Errorf("error from,%s", err)
+		}
+		runtime.Decode(acc.Address, &user)
+	}
+	if info.Margin == 0 {
+		info.Margin = DefEnergyMargin
+	}
+	trans := core.NewTransaction(chain, user)
+	trans.CreateRunApp(app, info.Cost, param)
+	return estimateAppEnergy(chain, *trans, info.Margin), nil
+}
+
+// TransactionAppEnergyPost estimate the energy of app call, the body is the same as TransactionRunAppPost,
+// the user(hex address) is used instead of the wallet if it is not empty
+func TransactionAppEnergyPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "fail to read body of request,", err, chainStr)
+		return
+	}
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	info := EnergyEstimateInfo{}
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "fail to Unmarshal body of request,", err)
+		return
+	}
+	out, err := estimateEnergy(chain, info)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+	writeJSON(w, out)
+}
+
+type rpcEnergyParam struct {
+	Chain uint64 `json:"chain"`
+	EnergyEstimateInfo
+}
+
+func rpcEstimateEnergy(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcEnergyParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	out, err := estimateEnergy(p.Chain, p.EnergyEstimateInfo)
+	if err != nil {
+		return nil, newRPCError(RPCInvalidParams, "%s", err)
+	}
+	return out, nil
+}
+
+func init() {
+	RegisterRPCMethod("govm_estimateEnergy", rpcEstimateEnergy)
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/govm-net/govm/handler"
+)
+
+// EventsResult the result of event query
+type EventsResult struct {
+	Events []handler.EventLog `json:"events"`
+	Next   string             `json:"next,omitempty"`
+}
+
+func parseEventQuery(form url.Values) (handler.EventQuery, error) {
+	var q handler.EventQuery
+	var err error
+	if v := form.Get("from_block"); v != "" {
+		q.FromBlock, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return q, fmt.Errorf("error from_block")
+		}
+	}
+	if v := form.Get("to_block"); v != "" {
+		q.ToBlock, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return q, fmt.Errorf("error to_block")
+		}
+	}
+	if v := form.Get("limit"); v != "" {
+		q.Limit, err = strconv.Atoi(v)
+		if err != nil {
+			return q, fmt.Errorf("error limit")
+		}
+	}
+	q.TransKey, err = hex.DecodeString(form.Get("trans"))
+	if err != nil {
+		return q, fmt.Errorf("error trans,must hex string")
+	}
+	q.App, err = hex.DecodeString(form.Get("app"))
+	if err != nil {
+		return q, fmt.Errorf("error app,must hex string")
+	}
+	q.Cursor, err = hex.DecodeString(form.Get("cursor"))
+	if err != nil {
+		return q, fmt.Errorf("error cursor,must hex string")
+	}
+	q.Struct = form.Get("struct")
+	q.Event = form.Get("event")
+	return q, nil
+}
+
+// EventsGet query the event log of apps,
+// filter by from_block/to_block/trans/app/struct/event, paginated by cursor and limit
+func EventsGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	q, err := parseEventQuery(r.Form)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+	var out EventsResult
+	var next []byte
+	out.Events, next = handler.QueryEventLog(chain, q)
+	if len(next) > 0 {
+		out.Next = hex.EncodeToString(next)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.Encode(out)
+}
+
+type rpcEventsParam struct {
+	Chain     uint64 `json:"chain"`
+	FromBlock uint64 `json:"from_block,omitempty"`
+	ToBlock   uint64 `json:"to_block,omitempty"`
+	Trans     string `json:"trans,omitempty"`
+	App       string `json:"app,omitempty"`
+	Struct    string `json:"struct,omitempty"`
+	Event     string `json:"event,omitempty"`
+	Cursor    string `json:"cursor,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+func rpcGetEvents(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcEventsParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("from_block", strconv.FormatUint(p.FromBlock, 10))
+	form.Set("to_block", strconv.FormatUint(p.ToBlock, 10))
+	form.Set("limit", strconv.Itoa(p.Limit))
+	form.Set("trans", p.Trans)
+	form.Set("app", p.App)
+	form.Set("cursor", p.Cursor)
+	form.Set("struct", p.Struct)
+	form.Set("event", p.Event)
+	q, err := parseEventQuery(form)
+	if err != nil {
+		return nil, newRPCError(RPCInvalidParams, "%s", err)
+	}
+	var out EventsResult
+	var next []byte
+	out.Events, next = handler.QueryEventLog(p.Chain, q)
+	if len(next) > 0 {
+		out.Next = hex.EncodeToString(next)
+	}
+	return out, nil
+}
+
+// TransactionReceiptGet get the receipt of transaction
+func TransactionReceiptGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	keyStr := r.Form.Get("key")
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	key, err := hex.DecodeString(keyStr)
+	if err != nil || len(key) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error key"))
+		return
+	}
+	out := handler.GetReceipt(chain, key)
+	if out == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "not found receipt.chain:%d,key:%x", chain, key)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.Encode(out)
+}
+
+func rpcGetTransactionReceipt(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcTransParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	key, err := decodeHexParam("key", p.Key)
+	if err != nil {
+		return nil, err
+	}
+	out := handler.GetReceipt(p.Chain, key)
+	if out == nil {
+		return nil, newRPCError(RPCServerError, "not found receipt.chain:%d,key:%x", p.Chain, key)
+	}
+	return out, nil
+}
+
+func init() {
+	RegisterRPCMethod("govm_getEvents", rpcGetEvents)
+	RegisterRPCMethod("govm_getTransactionReceipt", rpcGetTransactionReceipt)
+}
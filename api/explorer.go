@@ -0,0 +1,251 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/handler"
+)
+
+const (
+	maxExplorerLimit = 100
+	defExplorerLimit = 20
+)
+
+// AddrTransResult the result of address history
+type AddrTransResult struct {
+	Items []handler.AddrTrans `json:"items"`
+	Next  string              `json:"next,omitempty"`
+}
+
+// BlocksResult the result of block range, Next is the index of next page
+type BlocksResult struct {
+	Items []*blockInfo `json:"items"`
+	Next  uint64       `json:"next,omitempty"`
+}
+
+// BlockTransResult the transactions of block
+type BlockTransResult struct {
+	Chain    uint64       `json:"chain"`
+	Index    uint64       `json:"index"`
+	BlockKey string       `json:"block_key"`
+	Total    int          `json:"total"`
+	Items    []*TransInfo `json:"items"`
+	Next     int          `json:"next,omitempty"`
+}
+
+func parseLimit(v string) (int, error) {
+	if v == "" {
+		return defExplorerLimit, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("error limit")
+	}
+	if limit > maxExplorerLimit {
+		limit = maxExplorerLimit
+	}
+	return limit, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.Encode(v)
+}
+
+// AddressTransGet get the transactions sent or received by the address(newest first),
+// filter by from_block/to_block, paginated by cursor and limit
+func AddressTransGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	var addr core.Address
+	d, err := hex.DecodeString(r.Form.Get("address"))
+	if err != nil || len(d) != core.AddressLen {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error address"))
+		return
+	}
+	copy(addr[:], d)
+	var q handler.AddrTransQuery
+	if v := r.Form.Get("from_block"); v != "" {
+		q.FromBlock, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error from_block"))
+			return
+		}
+	}
+	if v := r.Form.Get("to_block"); v != "" {
+		q.ToBlock, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error to_block"))
+			return
+		}
+	}
+	q.Limit, err = parseLimit(r.Form.Get("limit"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+	q.Cursor, err = hex.DecodeString(r.Form.Get("cursor"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error cursor,must hex string"))
+		return
+	}
+	var out AddrTransResult
+	var next []byte
+	out.Items, next = handler.QueryAddrTrans(chain, addr, q)
+	if len(next) > 0 {
+		out.Next = hex.EncodeToString(next)
+	}
+	writeJSON(w, out)
+}
+
+// BlocksGet get the blocks of range [from,to](newest first), to is the last block if it is empty,
+// Next is the 'to' of next page
+func BlocksGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	var from, to uint64
+	if v := r.Form.Get("from"); v != "" {
+		from, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error from"))
+			return
+		}
+	}
+	last := core.GetLastBlockIndex(chain)
+	to = last
+	if v := r.Form.Get("to"); v != "" {
+		to, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error to"))
+			return
+		}
+		if to > last {
+			to = last
+		}
+	}
+	limit, err := parseLimit(r.Form.Get("limit"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+	if from == 0 {
+		from = 1
+	}
+	out := BlocksResult{Items: []*blockInfo{}}
+	for i := to; i >= from && i > 0; i-- {
+		if len(out.Items) >= limit {
+			out.Next = i
+			break
+		}
+		info := getBlockInfo(chain, core.GetTheBlockKey(chain, i))
+		if info == nil {
+			continue
+		}
+		out.Items = append(out.Items, info)
+	}
+	writeJSON(w, out)
+}
+
+// BlockTransGet get the transactions of the block(by index or key), paginated by offset and limit
+func BlockTransGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	var key []byte
+	if keyStr := r.Form.Get("key"); keyStr != "" {
+		key, err = hex.DecodeString(keyStr)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error key"))
+			return
+		}
+	} else {
+		index, err := strconv.ParseUint(r.Form.Get("index"), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error index"))
+			return
+		}
+		key = core.GetTheBlockKey(chain, index)
+	}
+	var offset int
+	if v := r.Form.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error offset"))
+			return
+		}
+	}
+	limit, err := parseLimit(r.Form.Get("limit"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+	var block *core.StBlock
+	if data := core.ReadBlockData(chain, key); len(data) > 0 {
+		block = core.DecodeBlock(data)
+	}
+	if block == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "not found block.chain:%d,key:%x\n", chain, key)
+		return
+	}
+	var list []core.Hash
+	if !block.TransListHash.Empty() {
+		list = core.ParseTransList(core.ReadTransList(chain, block.TransListHash[:]))
+	}
+	out := BlockTransResult{Chain: chain, Index: block.Index, Total: len(list)}
+	out.BlockKey = hex.EncodeToString(block.Key[:])
+	out.Items = []*TransInfo{}
+	for i := offset; i < len(list); i++ {
+		if len(out.Items) >= limit {
+			out.Next = i
+			break
+		}
+		tk := list[i]
+		info := getTransInfo(chain, tk[:], core.ReadTransactionData(chain, tk[:]))
+		if info == nil {
+			continue
+		}
+		out.Items = append(out.Items, info)
+	}
+	writeJSON(w, out)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/govm-net/govm/logger"
+)
+
+// LogLevels the levels of log
+type LogLevels struct {
+	Default    string            `json:"default"`
+	Subsystems map[string]string `json:"subsystems"`
+}
+
+// LogLevelInfo change the level of log, change the default level if subsystem is empty,
+// the level of subsystem follows the default level if level is empty
+type LogLevelInfo struct {
+	Subsystem string `json:"subsystem,omitempty"`
+	Level     string `json:"level,omitempty"`
+}
+
+func getLogLevels() LogLevels {
+	return LogLevels{logger.GetLevel("").String(), logger.Levels()}
+}
+
+func setLogLevel(info LogLevelInfo) (LogLevels, error) {
+	if info.Level == "" {
+		if info.Subsystem == "" {
+			return LogLevels{}, fmt.Errorf("empty level")
+		}
+		logger.ResetLevel(info.Subsystem)
+		apiLog.Info("reset log level", "subsystem", info.Subsystem)
+		return getLogLevels(), nil
+	}
+	level, err := logger.ParseLevel(info.Level)
+	if err != nil {
+		return LogLevels{}, err
+	}
+	logger.SetLevel(info.Subsystem, level)
+	apiLog.Info("set log level", "subsystem", info.Subsystem, "level", level.String())
+	return getLogLevels(), nil
+}
+
+// LogLevelsGet get the level of log of every subsystem
+func LogLevelsGet(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, getLogLevels())
+}
+
+// LogLevelsPost change the level of log at runtime, it is not saved to the configure
+func LogLevelsPost(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "fail to read body of request,", err)
+		return
+	}
+	info := LogLevelInfo{}
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "fail to Unmarshal body of request,", err)
+		return
+	}
+	out, err := setLogLevel(info)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+	writeJSON(w, out)
+}
+
+func rpcGetLogLevels(params json.RawMessage) (interface{}, *RPCError) {
+	return getLogLevels(), nil
+}
+
+func rpcSetLogLevel(params json.RawMessage) (interface{}, *RPCError) {
+	var p LogLevelInfo
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	out, err := setLogLevel(p)
+	if err != nil {
+		return nil, newRPCError(RPCInvalidParams, "%s", err)
+	}
+	return out, nil
+}
+
+func init() {
+	RegisterRPCMethod("govm_getLogLevels", rpcGetLogLevels)
+	RegisterRPCMethod("govm_setLogLevel", rpcSetLogLevel)
+}
@@ -10,27 +10,25 @@ package api
 import (
 	"expvar"
 	"github.com/govm-net/govm/conf"
-	"log"
+	"github.com/govm-net/govm/logger"
 	"net/http"
 	"time"
 )
 
 var stat = expvar.NewMap("restful")
+var apiLog = logger.New(logger.SubAPI)
 
-// Logger print log
+// Logger print log of request, the level is info if restful_log is true, otherwise debug
 func Logger(inner http.Handler, name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		inner.ServeHTTP(w, r)
 		stat.Add(name, 1)
+		level := logger.LevelDebug
 		if conf.GetConf().RestfulLog {
-			log.Printf(
-				"%s %s %s %s",
-				r.Method,
-				r.RequestURI,
-				name,
-				time.Since(start),
-			)
+			level = logger.LevelInfo
 		}
+		apiLog.Log(0, level, "request", "method", r.Method, "uri", r.RequestURI,
+			"name", name, "remote", r.RemoteAddr, "duration", time.Since(start).String())
 	})
 }
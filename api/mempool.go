@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/govm-net/govm/conf"
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/handler"
+)
+
+// MempoolResult the transactions waiting for mining
+type MempoolResult struct {
+	Chain uint64              `json:"chain"`
+	Total int                 `json:"total"`
+	Size  int                 `json:"size_limit"`
+	Age   uint64              `json:"max_age"`
+	Items []handler.PoolTrans `json:"items"`
+	Next  int                 `json:"next,omitempty"`
+}
+
+// FeeEstimateResult the result of fee estimation, Energy is the suggested energy of the size
+type FeeEstimateResult struct {
+	handler.FeeEstimate
+	Energy map[string]uint64 `json:"energy,omitempty"`
+}
+
+// MempoolGet list the transactions waiting for mining, paginated by offset and limit
+func MempoolGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	var offset int
+	if v := r.Form.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error offset"))
+			return
+		}
+	}
+	limit, err := parseLimit(r.Form.Get("limit"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err)
+		return
+	}
+	out := MempoolResult{Chain: chain}
+	out.Total, out.Items = handler.ListTransPool(chain, offset, limit)
+	if offset+len(out.Items) < out.Total {
+		out.Next = offset + len(out.Items)
+	}
+	c := conf.GetConf()
+	out.Size = c.MempoolSize
+	if out.Size <= 0 {
+		out.Size = handler.DefMempoolSize
+	}
+	out.Age = c.MempoolMaxAge
+	if out.Age == 0 {
+		out.Age = handler.DefMempoolMaxAge
+	}
+	writeJSON(w, out)
+}
+
+// MempoolDelete drop the transaction from mempool
+func MempoolDelete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	key, err := hex.DecodeString(r.Form.Get("key"))
+	if err != nil || len(key) != core.HashLen {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error key"))
+		return
+	}
+	err = handler.DropTransaction(chain, key, getCaller(r).Name)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintln(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "drop the transaction:%x\n", key)
+}
+
+// MempoolRejectedGet get the reason why the transaction was rejected by core.CheckTransaction
+func MempoolRejectedGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	key, err := hex.DecodeString(r.Form.Get("key"))
+	if err != nil || len(key) != core.HashLen {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error key"))
+		return
+	}
+	info := handler.GetRejectInfo(chain, key)
+	if info == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "not found the rejected transaction.chain:%d,key:%x\n", chain, key)
+		return
+	}
+	writeJSON(w, info)
+}
+
+// MempoolDroppedGet get the record of the transaction dropped from mempool by the operator
+func MempoolDroppedGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	key, err := hex.DecodeString(r.Form.Get("key"))
+	if err != nil || len(key) != core.HashLen {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error key"))
+		return
+	}
+	info := handler.GetDropInfo(chain, key)
+	if info == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "not found the dropped transaction.chain:%d,key:%x\n", chain, key)
+		return
+	}
+	writeJSON(w, info)
+}
+
+// FeeEstimateGet estimate the fee(energy) per byte by the transactions of recent blocks,
+// the energy of transaction is suggested if size is not empty
+func FeeEstimateGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	r.ParseForm()
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	var blocks int
+	if v := r.Form.Get("blocks"); v != "" {
+		blocks, err = strconv.Atoi(v)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error blocks"))
+			return
+		}
+	}
+	var size uint64
+	if v := r.Form.Get("size"); v != "" {
+		size, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("error size"))
+			return
+		}
+	}
+	writeJSON(w, estimateFee(chain, blocks, size))
+}
+
+func estimateFee(chain uint64, blocks int, size uint64) FeeEstimateResult {
+	out := FeeEstimateResult{FeeEstimate: handler.EstimateFee(chain, blocks)}
+	if size > 0 {
+		out.Energy = make(map[string]uint64)
+		energy := func(fee float64) uint64 {
+			return uint64(fee*float64(size)) + 1
+		}
+		out.Energy["low"] = energy(out.Low)
+		out.Energy["median"] = energy(out.Median)
+		out.Energy["high"] = energy(out.High)
+	}
+	return out
+}
+
+type rpcFeeParam struct {
+	Chain  uint64 `json:"chain"`
+	Blocks int    `json:"blocks,omitempty"`
+	Size   uint64 `json:"size,omitempty"`
+}
+
+func rpcEstimateFee(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcFeeParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return estimateFee(p.Chain, p.Blocks, p.Size), nil
+}
+
+func init() {
+	RegisterRPCMethod("govm_estimateFee", rpcEstimateFee)
+}
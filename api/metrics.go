@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/handler"
+	"github.com/govm-net/govm/metrics"
+)
+
+func init() {
+	metrics.NewGaugeFunc("govm_chain_height", "index of the last block", chainSamples(func(chain uint64) float64 {
+		return float64(core.GetLastBlockIndex(chain))
+	}))
+	metrics.NewGaugeFunc("govm_block_hashpower", "hash power(TReliability) of the last block", chainSamples(func(chain uint64) float64 {
+		key := core.GetTheBlockKey(chain, 0)
+		if len(key) == 0 {
+			return 0
+		}
+		return float64(handler.ReadBlockReliability(chain, key).HashPower)
+	}))
+	metrics.NewGaugeFunc("govm_blocks_hashpower_avg", "average hash power of the recent blocks", chainSamples(func(chain uint64) float64 {
+		return float64(handler.GetHashPowerOfBlocks(chain))
+	}))
+	metrics.NewGaugeFunc("govm_my_hashpower_avg", "average hash power of the blocks mined by self", chainSamples(func(chain uint64) float64 {
+		return float64(handler.GetMyHashPower(chain))
+	}))
+	metrics.NewGaugeFunc("govm_trans_pool_size", "number of transactions waiting for mining", chainSamples(func(chain uint64) float64 {
+		return float64(handler.GetTransPoolSize(chain))
+	}))
+	metrics.NewGaugeFunc("govm_peers", "number of connected peers", func() []metrics.Sample {
+		return []metrics.Sample{{Value: float64(handler.NodesCount)}}
+	})
+	metrics.NewGaugeFunc("govm_miner_connections", "number of websocket connections of miners", func() []metrics.Sample {
+		return []metrics.Sample{{Value: float64(minerNum)}}
+	})
+}
+
+// chainSamples return the callback which get the value of every chain
+func chainSamples(cb func(chain uint64) float64) func() []metrics.Sample {
+	return func() []metrics.Sample {
+		var out []metrics.Sample
+		for _, chain := range handler.GetChains() {
+			out = append(out, metrics.Sample{
+				Labels: []string{"chain", strconv.FormatUint(chain, 10)},
+				Value:  cb(chain),
+			})
+		}
+		return out
+	}
+}
+
+// MetricsGet get the metrics of node, prometheus text format
+func MetricsGet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.WriteHeader(http.StatusOK)
+	metrics.WriteTo(w)
+}
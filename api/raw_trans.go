@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/runtime"
+)
+
+// the code of rejected raw transaction
+const (
+	RejectDecode = "decode"
+	RejectSign   = "sign"
+	RejectUser   = "user"
+	RejectEnergy = "energy"
+	RejectChain  = "chain"
+	RejectTime   = "time"
+	RejectCheck  = "check"
+	RejectRetry  = "retry"
+)
+
+const maxRawTransSize = 100 * 1024
+
+// RawTransResult the result of raw transaction submission
+type RawTransResult struct {
+	Chain    uint64 `json:"chain,omitempty"`
+	TransKey string `json:"trans_key,omitempty"`
+	Accepted bool   `json:"accepted"`
+	Exist    bool   `json:"exist,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func isHexData(in []byte) bool {
+	if len(in) == 0 || len(in)%2 != 0 {
+		return false
+	}
+	for _, c := range in {
+		switch {
+		case '0' <= c && c <= '9':
+		case 'a' <= c && c <= 'f':
+		case 'A' <= c && c <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// submitRawTransaction check the signed transaction(StTrans.Output()) and send it to handler,
+// the handler check it by core.CheckTransaction and broadcast it
+func submitRawTransaction(chain uint64, data []byte) RawTransResult {
+	out := RawTransResult{Chain: chain}
+	if len(data) > 0 {
+		out.TransKey = hex.EncodeToString(runtime.GetHash(data))
+	}
+	reject := func(code string, format string, args ...interface{}) RawTransResult {
+		stat.Add("raw_trans_reject", 1)
+		out.Code = code
+		out.Reason = fmt.Sprintf(format, args...)
+		return out
+	}
+	if len(data) > maxRawTransSize {
+		return reject(RejectDecode, "transaction too large,%d", len(data))
+	}
+	trans, err := core.DecodeTransaction(data)
+	switch err {
+	case nil:
+	case core.ErrTransSign:
+		return reject(RejectSign, "%s", err)
+	case core.ErrTransUser:
+		return reject(RejectUser, "%s", err)
+	case core.ErrTransEnergy:
+		return reject(RejectEnergy, "%s,need:%d", err, len(data))
+	default:
+		return reject(RejectDecode, "%s", err)
+	}
+	if trans.Chain != chain {
+		return reject(RejectChain, "different chain,hope:%d,get:%d", chain, trans.Chain)
+	}
+	now := uint64(time.Now().Unix() * 1000)
+	if trans.Time > now+3600*1000 {
+		return reject(RejectTime, "future transaction,time:%d", trans.Time)
+	}
+	if core.IsExistTransaction(chain, trans.Key) {
+		out.Accepted = true
+		out.Exist = true
+		return out
+	}
+	_, err = newTransaction(chain, data)
+	if runtime.IsRetryError(err) {
+		return reject(RejectRetry, "%s", err)
+	} else if err != nil {
+		return reject(RejectCheck, "%s", err)
+	}
+	stat.Add("raw_trans_accept", 1)
+	out.Accepted = true
+	return out
+}
+
+// TransactionRawPost submit the transaction signed by user,
+// the body is the data of StTrans.Output(), raw bytes or hex string
+func TransactionRawPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 2*maxRawTransSize+2))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "fail to read body of request,", err, chainStr)
+		return
+	}
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	if d := bytes.TrimSpace(data); isHexData(d) {
+		data, _ = hex.DecodeString(string(d))
+	}
+	rst := submitRawTransaction(chain, data)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	switch {
+	case rst.Accepted:
+		w.WriteHeader(http.StatusOK)
+	case rst.Code == RejectRetry:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	enc := json.NewEncoder(w)
+	enc.Encode(rst)
+}
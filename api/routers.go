@@ -47,6 +47,7 @@ func NewRouter() *mux.Router {
 	for _, route := range routes {
 		var handler http.Handler
 		handler = route.HandlerFunc
+		handler = Auth(handler, route.Name, routeScope(route.Name, route.Method))
 		handler = Logger(handler, route.Name)
 
 		router.
@@ -56,12 +57,12 @@ func NewRouter() *mux.Router {
 			Handler(handler)
 	}
 	for _, route := range wsRoutes {
-		router.Handle(route.Pattern, websocket.Handler(route.HandlerFunc))
+		router.Handle(route.Pattern, Auth(websocket.Handler(route.HandlerFunc), route.Name, ScopeRead))
 	}
 	// http.Handle("/readWrite", websocket.Handler(WSBlockForMining))
 
-	router.Methods("GET").Path("/api/v1/").Name("Index").HandlerFunc(Index)
-	router.Handle("/debug/vars", Logger(expvar.Handler(), "expvar"))
+	router.Methods("GET").Path("/api/v1/").Name("Index").Handler(Auth(http.HandlerFunc(Index), "Index", ScopeRead))
+	router.Handle("/debug/vars", Logger(Auth(expvar.Handler(), "expvar", ScopeRead), "expvar"))
 	router.Methods("GET").Name("static").Handler(http.FileServer(http.Dir("./static/")))
 
 	return router
@@ -90,6 +91,55 @@ var routes = Routes{
 		TransactionNew,
 	},
 
+	Route{
+		"MempoolGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/mempool",
+		MempoolGet,
+	},
+
+	Route{
+		"MempoolDelete",
+		strings.ToUpper("Delete"),
+		"/api/v1/{chain}/mempool",
+		MempoolDelete,
+	},
+
+	Route{
+		"MempoolRejectedGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/mempool/rejected",
+		MempoolRejectedGet,
+	},
+
+	Route{
+		"MempoolDroppedGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/mempool/dropped",
+		MempoolDroppedGet,
+	},
+
+	Route{
+		"FeeEstimateGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/fee/estimate",
+		FeeEstimateGet,
+	},
+
+	Route{
+		"TransactionRawPost",
+		strings.ToUpper("Post"),
+		"/api/v1/{chain}/transaction/raw",
+		TransactionRawPost,
+	},
+
+	Route{
+		"TransactionSimulatePost",
+		strings.ToUpper("Post"),
+		"/api/v1/{chain}/transaction/simulate",
+		TransactionSimulatePost,
+	},
+
 	Route{
 		"TransactionMovePost",
 		strings.ToUpper("Post"),
@@ -125,6 +175,13 @@ var routes = Routes{
 		TransactionRunAppPost,
 	},
 
+	Route{
+		"TransactionAppEnergyPost",
+		strings.ToUpper("Post"),
+		"/api/v1/{chain}/transaction/app/energy",
+		TransactionAppEnergyPost,
+	},
+
 	Route{
 		"TransactionAppLifePost",
 		strings.ToUpper("Post"),
@@ -146,6 +203,13 @@ var routes = Routes{
 		TransactionInfoGet,
 	},
 
+	Route{
+		"TransactionReceiptGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/transaction/receipt",
+		TransactionReceiptGet,
+	},
+
 	Route{
 		"BlockMinePost",
 		strings.ToUpper("Post"),
@@ -160,6 +224,27 @@ var routes = Routes{
 		BlockInfoGet,
 	},
 
+	Route{
+		"BlocksGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/blocks",
+		BlocksGet,
+	},
+
+	Route{
+		"BlockTransGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/block/transactions",
+		BlockTransGet,
+	},
+
+	Route{
+		"AddressTransGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/address/transactions",
+		AddressTransGet,
+	},
+
 	Route{
 		"TrustedBlockGet",
 		strings.ToUpper("Get"),
@@ -292,6 +377,55 @@ var routes = Routes{
 		"/api/v1/time",
 		TimeGet,
 	},
+	Route{
+		"LogLevelsGet",
+		strings.ToUpper("Get"),
+		"/api/v1/log/levels",
+		LogLevelsGet,
+	},
+	Route{
+		"LogLevelsPost",
+		strings.ToUpper("Post"),
+		"/api/v1/log/levels",
+		LogLevelsPost,
+	},
+	Route{
+		"SpendingGet",
+		strings.ToUpper("Get"),
+		"/api/v1/spending",
+		SpendingGet,
+	},
+
+	Route{
+		"ConfigReloadPost",
+		strings.ToUpper("Post"),
+		"/api/v1/config/reload",
+		ConfigReloadPost,
+	},
+	Route{
+		"EventsGet",
+		strings.ToUpper("Get"),
+		"/api/v1/{chain}/events",
+		EventsGet,
+	},
+	Route{
+		"JSONRPC",
+		strings.ToUpper("Post"),
+		"/rpc",
+		JSONRPC,
+	},
+	Route{
+		"Metrics",
+		strings.ToUpper("Get"),
+		"/metrics",
+		MetricsGet,
+	},
+	Route{
+		"DevnetMinePost",
+		strings.ToUpper("Post"),
+		"/api/v1/{chain}/devnet/mine",
+		DevnetMinePost,
+	},
 }
 
 var wsRoutes = WSRoutes{
@@ -300,4 +434,9 @@ var wsRoutes = WSRoutes{
 		"/api/v1/{chain}/ws/mining",
 		WSBlockForMining,
 	},
+	WSRoute{
+		"subscribe",
+		"/api/v1/{chain}/ws/subscribe",
+		WSSubscribe,
+	},
 }
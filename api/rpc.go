@@ -0,0 +1,342 @@
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/runtime"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+	RPCServerError    = -32000
+	RPCUnauthorized   = -32001
+)
+
+// RPCRequest request of JSON-RPC 2.0
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// RPCError error of JSON-RPC 2.0
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%d:%s", e.Code, e.Message)
+}
+
+// RPCResponse response of JSON-RPC 2.0
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// maxRPCSize the max size of the body of JSON-RPC, a batch may contain some raw transactions(hex)
+const maxRPCSize = 8 * maxRawTransSize
+
+type rpcMethod func(params json.RawMessage) (interface{}, *RPCError)
+
+var rpcMethods = map[string]rpcMethod{
+	"govm_getAccount":         rpcGetAccount,
+	"govm_sendRawTransaction": rpcSendRawTransaction,
+	"govm_getBlock":           rpcGetBlock,
+	"govm_getTransaction":     rpcGetTransaction,
+	"govm_getAppInfo":         rpcGetAppInfo,
+	"govm_getData":            rpcGetData,
+}
+
+// RegisterRPCMethod register method of JSON-RPC
+func RegisterRPCMethod(name string, f func(params json.RawMessage) (interface{}, *RPCError)) {
+	rpcMethods[name] = f
+}
+
+func newRPCError(code int, format string, args ...interface{}) *RPCError {
+	return &RPCError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// JSONRPC the endpoint of JSON-RPC 2.0, support batch request.
+// the methods are the query routes and the raw transaction of REST api,
+// the routes which sign the transaction by the wallet of node(transaction/move, transfer, miner,
+// app/new, app/run, app/life, chain, admin and crypto/sign) are not exposed,
+// the client signs the transaction and sends it by govm_sendRawTransaction
+func JSONRPC(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRPCSize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "fail to read body of request,", err)
+		return
+	}
+	var out interface{}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var reqs []json.RawMessage
+		err = json.Unmarshal(data, &reqs)
+		if err != nil {
+			out = RPCResponse{JSONRPC: "2.0", Error: newRPCError(RPCParseError, "parse error")}
+		} else if len(reqs) == 0 {
+			out = RPCResponse{JSONRPC: "2.0", Error: newRPCError(RPCInvalidRequest, "empty batch")}
+		} else {
+			var list []RPCResponse
+			for _, req := range reqs {
+				rsp := processRPC(r, req)
+				if rsp != nil {
+					list = append(list, *rsp)
+				}
+			}
+			if len(list) > 0 {
+				out = list
+			}
+		}
+	} else if rsp := processRPC(r, data); rsp != nil {
+		out = rsp
+	}
+	if out == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.Encode(out)
+}
+
+// processRPC process one request, return nil if the request is notification.
+// the scope of the caller is checked, the state-changing methods are written to the audit log
+func processRPC(r *http.Request, data []byte) *RPCResponse {
+	out := &RPCResponse{JSONRPC: "2.0", ID: json.RawMessage("null")}
+	req := RPCRequest{}
+	err := json.Unmarshal(data, &req)
+	if err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			out.Error = newRPCError(RPCParseError, "parse error")
+		} else {
+			out.Error = newRPCError(RPCInvalidRequest, "invalid request")
+		}
+		return out
+	}
+	if len(req.ID) > 0 {
+		out.ID = req.ID
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		out.Error = newRPCError(RPCInvalidRequest, "invalid request")
+		return out
+	}
+	f, ok := rpcMethods[req.Method]
+	scope := rpcScope(req.Method)
+	if !ok {
+		out.Error = newRPCError(RPCMethodNotFound, "method not found:%s", req.Method)
+	} else if !hasScope(getCaller(r), scope) {
+		stat.Add("forbidden", 1)
+		out.Error = newRPCError(RPCUnauthorized, "forbidden, need scope:%s", scope)
+	} else {
+		out.Result, out.Error = callRPC(f, req.Params)
+		if out.Error == nil && out.Result == nil {
+			out.Result = json.RawMessage("null")
+		}
+	}
+	if ok && scope != ScopeRead {
+		rec := AuditRecord{Route: "JSONRPC", Scope: scope, RPCMethod: req.Method, Status: http.StatusOK}
+		if out.Error != nil {
+			rec.Error = out.Error.Error()
+		}
+		audit(r, rec)
+	}
+	if len(req.ID) == 0 {
+		return nil
+	}
+	return out
+}
+
+func callRPC(f rpcMethod, params json.RawMessage) (rst interface{}, rErr *RPCError) {
+	defer func() {
+		e := recover()
+		if e != nil {
+			rst = nil
+			rErr = newRPCError(RPCInternalError, "%v", e)
+		}
+	}()
+	return f(params)
+}
+
+// decodeParams decode params, support object({"chain":1}) and array([{"chain":1}])
+func decodeParams(params json.RawMessage, out interface{}) *RPCError {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 {
+		return newRPCError(RPCInvalidParams, "need params")
+	}
+	if params[0] == '[' {
+		var list []json.RawMessage
+		err := json.Unmarshal(params, &list)
+		if err != nil || len(list) != 1 {
+			return newRPCError(RPCInvalidParams, "invalid params")
+		}
+		params = list[0]
+	}
+	err := json.Unmarshal(params, out)
+	if err != nil {
+		return newRPCError(RPCInvalidParams, "invalid params:%s", err)
+	}
+	return nil
+}
+
+func decodeHexParam(name, in string) ([]byte, *RPCError) {
+	out, err := hex.DecodeString(in)
+	if err != nil || len(out) == 0 {
+		return nil, newRPCError(RPCInvalidParams, "error %s,must hex string", name)
+	}
+	return out, nil
+}
+
+type rpcAccountParam struct {
+	Chain   uint64 `json:"chain"`
+	Address string `json:"address"`
+}
+
+func rpcGetAccount(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcAccountParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	addr, err := decodeHexParam("address", p.Address)
+	if err != nil {
+		return nil, err
+	}
+	out := Account{}
+	out.Chain = p.Chain
+	out.Address = p.Address
+	out.Cost = core.GetUserCoin(p.Chain, addr)
+	return out, nil
+}
+
+type rpcRawTransParam struct {
+	Chain uint64 `json:"chain"`
+	Data  string `json:"data"`
+}
+
+func rpcSendRawTransaction(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcRawTransParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	data, rErr := decodeHexParam("data", p.Data)
+	if rErr != nil {
+		return nil, rErr
+	}
+	rst := submitRawTransaction(p.Chain, data)
+	if !rst.Accepted {
+		return nil, &RPCError{Code: RPCServerError, Message: rst.Reason, Data: rst}
+	}
+	return rst.TransKey, nil
+}
+
+type rpcBlockParam struct {
+	Chain uint64 `json:"chain"`
+	Index uint64 `json:"index,omitempty"`
+	Key   string `json:"key,omitempty"`
+}
+
+func rpcGetBlock(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcBlockParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	var key []byte
+	if p.Key != "" {
+		var err *RPCError
+		key, err = decodeHexParam("key", p.Key)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		key = core.GetTheBlockKey(p.Chain, p.Index)
+	}
+	info := getBlockInfo(p.Chain, key)
+	if info == nil {
+		return nil, newRPCError(RPCServerError, "not found.chain:%d,key:%x,index:%d", p.Chain, key, p.Index)
+	}
+	return info, nil
+}
+
+type rpcTransParam struct {
+	Chain uint64 `json:"chain"`
+	Key   string `json:"key"`
+}
+
+func rpcGetTransaction(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcTransParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	key, err := decodeHexParam("key", p.Key)
+	if err != nil {
+		return nil, err
+	}
+	data := core.ReadTransactionData(p.Chain, key)
+	if len(data) == 0 {
+		return nil, newRPCError(RPCServerError, "not found.chain:%d,key:%x", p.Chain, key)
+	}
+	info := getTransInfo(p.Chain, key, data)
+	if info == nil {
+		return nil, newRPCError(RPCServerError, "error transaction.chain:%d,key:%x", p.Chain, key)
+	}
+	return info, nil
+}
+
+func rpcGetAppInfo(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcTransParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	key, err := decodeHexParam("key", p.Key)
+	if err != nil {
+		return nil, err
+	}
+	info := core.GetAppInfoOfChain(p.Chain, key)
+	if info == nil {
+		return nil, newRPCError(RPCServerError, "not found.chain:%d,key:%x", p.Chain, key)
+	}
+	return info, nil
+}
+
+type rpcDataParam struct {
+	Chain uint64 `json:"chain"`
+	DataInfo
+}
+
+func rpcGetData(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcDataParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.AppName == "" {
+		p.AppName = "ff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	}
+	key, err := hex.DecodeString(p.Key)
+	if err != nil {
+		return nil, newRPCError(RPCInvalidParams, "error key,must hex string")
+	}
+	val, life := runtime.GetValue(p.Chain, p.IsDBData, p.AppName, p.StructName, key)
+	out := p.DataInfo
+	out.Value = hex.EncodeToString(val)
+	out.Life = life
+	return out, nil
+}
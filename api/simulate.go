@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	core "github.com/govm-net/govm/core"
+)
+
+// SimulateEvent the event emitted by the simulated transaction
+type SimulateEvent struct {
+	App    string   `json:"app,omitempty"`
+	Struct string   `json:"struct,omitempty"`
+	Event  string   `json:"event,omitempty"`
+	Params []string `json:"params,omitempty"`
+}
+
+// SimulateKey the key of db or log written by the simulated transaction
+type SimulateKey struct {
+	App    string `json:"app,omitempty"`
+	Struct string `json:"struct,omitempty"`
+	Key    string `json:"key,omitempty"`
+	IsLog  bool   `json:"is_log,omitempty"`
+}
+
+// SimulateResult the result of simulating transaction
+type SimulateResult struct {
+	Chain      uint64          `json:"chain,omitempty"`
+	TransKey   string          `json:"trans_key,omitempty"`
+	Success    bool            `json:"success"`
+	Error      string          `json:"error,omitempty"`
+	EnergyUsed uint64          `json:"energy_used"`
+	Events     []SimulateEvent `json:"events,omitempty"`
+	Keys       []SimulateKey   `json:"keys,omitempty"`
+}
+
+// simulateTransaction run the transaction(StTrans.Output()) without persisting,
+// the sign is not verified if it is empty
+func simulateTransaction(chain uint64, data []byte) SimulateResult {
+	out := SimulateResult{Chain: chain}
+	if len(data) > maxRawTransSize {
+		out.Error = fmt.Sprintf("transaction too large,%d", len(data))
+		return out
+	}
+	rst := core.SimulateTransaction(chain, data)
+	out.TransKey = hex.EncodeToString(rst.Key[:])
+	out.Success = rst.Error == ""
+	out.Error = rst.Error
+	out.EnergyUsed = rst.Used
+	for _, it := range rst.Events {
+		e := SimulateEvent{App: hex.EncodeToString(it.App), Struct: it.Struct, Event: it.Event}
+		for _, p := range it.Params {
+			e.Params = append(e.Params, hex.EncodeToString(p))
+		}
+		out.Events = append(out.Events, e)
+	}
+	for _, it := range rst.Keys {
+		k := SimulateKey{hex.EncodeToString(it.App), it.Struct, hex.EncodeToString(it.Key), it.IsLog}
+		out.Keys = append(out.Keys, k)
+	}
+	return out
+}
+
+// TransactionSimulatePost run the transaction without persisting, return the result,
+// the body is the data of StTrans.Output()(raw bytes or hex string), the sign can be empty
+func TransactionSimulatePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chainStr := vars["chain"]
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 2*maxRawTransSize+2))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "fail to read body of request,", err, chainStr)
+		return
+	}
+	chain, err := strconv.ParseUint(chainStr, 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error chain"))
+		return
+	}
+	if d := bytes.TrimSpace(data); isHexData(d) {
+		data, _ = hex.DecodeString(string(d))
+	}
+	writeJSON(w, simulateTransaction(chain, data))
+}
+
+func rpcSimulateTransaction(params json.RawMessage) (interface{}, *RPCError) {
+	var p rpcRawTransParam
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	data, rErr := decodeHexParam("data", p.Data)
+	if rErr != nil {
+		return nil, rErr
+	}
+	return simulateTransaction(p.Chain, data), nil
+}
+
+func init() {
+	RegisterRPCMethod("govm_simulateTransaction", rpcSimulateTransaction)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/govm-net/govm/approval"
+	"github.com/govm-net/govm/conf"
+	"github.com/govm-net/govm/database"
+)
+
+// the spending of the wallet of node, it is checked by spendingPolicy
+var walletSpending = &approval.Spending{Store: new(spendingStore)}
+
+const ldbSpending = "spending" //from:spent(8 bytes)+day
+
+// spendingStore save the spending of today in spending.db, so the daily limit is not reset by restart
+type spendingStore struct {
+	once sync.Once
+	db   *database.LDB
+}
+
+func (s *spendingStore) open() *database.LDB {
+	s.once.Do(func() {
+		s.db = database.NewLDB("spending.db", 100)
+		if s.db == nil {
+			apiLog.Error("fail to open ldb", "file", "spending.db")
+		}
+	})
+	return s.db
+}
+
+func (s *spendingStore) Load(day, from string) uint64 {
+	db := s.open()
+	if db == nil {
+		return 0
+	}
+	v := db.LGet(0, ldbSpending, []byte(from))
+	if len(v) < 8 || string(v[8:]) != day {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+func (s *spendingStore) Save(day, from string, spent uint64) {
+	db := s.open()
+	if db == nil {
+		return
+	}
+	v := make([]byte, 8, 8+len(day))
+	binary.BigEndian.PutUint64(v, spent)
+	db.LSet(0, ldbSpending, []byte(from), append(v, day...))
+}
+
+// spendingPolicy the spending policy of the wallet of node(spending of conf.json),
+// it is the first approver, so the violations are rejected before the transaction is created
+type spendingPolicy struct {
+	c conf.SpendingConf
+}
+
+// limited whether any limit is set
+func (p spendingPolicy) limited() bool {
+	return p.c.DailyLimit > 0 || p.c.TransLimit > 0 || len(p.c.Payees) > 0 ||
+		len(p.c.Chains) > 0 || len(p.c.Ops) > 0
+}
+
+func (p spendingPolicy) Approve(req approval.Request) error {
+	if len(p.c.Ops) > 0 && !approval.NewSet(p.c.Ops)[req.Ops] {
+		return fmt.Errorf("%w by spending policy: ops not allowed,%s", approval.ErrRejected, req.Ops)
+	}
+	// the message signed by the wallet may be a transaction(sent by /transaction/raw), it bypasses the limits
+	if req.Ops == approval.OpsSign && p.limited() && len(p.c.Ops) == 0 {
+		return fmt.Errorf("%w by spending policy: sign is not allowed with limits, add sign to ops", approval.ErrRejected)
+	}
+	if len(p.c.Chains) > 0 && req.Chain != 0 {
+		var found bool
+		for _, it := range p.c.Chains {
+			if it == req.Chain {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w by spending policy: chain not allowed,%d", approval.ErrRejected, req.Chain)
+		}
+	}
+	if req.Ops == approval.OpsTransfer && len(p.c.Payees) > 0 &&
+		!approval.NewSet(p.c.Payees)[strings.ToLower(req.Peer)] {
+		return fmt.Errorf("%w by spending policy: payee not allowed,%s", approval.ErrRejected, req.Peer)
+	}
+	amount, err := req.Amount()
+	if err != nil {
+		return fmt.Errorf("%w by spending policy: %s", approval.ErrRejected, err)
+	}
+	if p.c.TransLimit > 0 && amount > p.c.TransLimit {
+		return fmt.Errorf("%w by spending policy: over transaction limit,limit:%d,hope:%d",
+			approval.ErrRejected, p.c.TransLimit, amount)
+	}
+	if p.c.DailyLimit > 0 {
+		if err = walletSpending.Reserve(req.From, amount, p.c.DailyLimit); err != nil {
+			return fmt.Errorf("%w by spending policy: %s", approval.ErrRejected, err)
+		}
+	}
+	return nil
+}
+
+func (p spendingPolicy) Cancel(req approval.Request) {
+	if amount, err := req.Amount(); err == nil && p.c.DailyLimit > 0 {
+		walletSpending.Release(req.From, amount)
+	}
+}
+
+// releaseSpending release the spending of the approved request, it is called if fail to send the transaction
+func releaseSpending(req approval.Request) {
+	spendingPolicy{conf.GetConf().Spending}.Cancel(req)
+}
+
+// SpendingInfo the spending policy and the remaining allowance of the account today(UTC),
+// the limit is unlimited if it is 0
+type SpendingInfo struct {
+	From       string   `json:"from"`
+	DailyLimit uint64   `json:"daily_limit"`
+	Spent      uint64   `json:"spent"`
+	Remaining  uint64   `json:"remaining"`
+	TransLimit uint64   `json:"trans_limit"`
+	Payees     []string `json:"payees,omitempty"`
+	Chains     []uint64 `json:"chains,omitempty"`
+	Ops        []string `json:"ops,omitempty"`
+}
+
+func getSpending(from string) (SpendingInfo, error) {
+	acc, err := conf.GetWallet(from)
+	if err != nil {
+		return SpendingInfo{}, err
+	}
+	s := conf.GetConf().Spending
+	out := SpendingInfo{
+		From:       hex.EncodeToString(acc.Address),
+		DailyLimit: s.DailyLimit,
+		TransLimit: s.TransLimit,
+		Payees:     s.Payees,
+		Chains:     s.Chains,
+		Ops:        s.Ops,
+	}
+	out.Spent = walletSpending.Spent(out.From)
+	if out.DailyLimit > out.Spent {
+		out.Remaining = out.DailyLimit - out.Spent
+	}
+	return out, nil
+}
+
+// SpendingGet get the spending policy and the remaining allowance of the wallet of node,
+// the query from is the account of keystore, default the wallet
+func SpendingGet(w http.ResponseWriter, r *http.Request) {
+	out, err := getSpending(r.URL.Query().Get("from"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "error from,", err)
+		return
+	}
+	writeJSON(w, out)
+}
+
+func rpcGetSpending(params json.RawMessage) (interface{}, *RPCError) {
+	var p struct {
+		From string `json:"from"`
+	}
+	if len(params) > 0 {
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+	}
+	out, err := getSpending(p.From)
+	if err != nil {
+		return nil, newRPCError(RPCInvalidParams, "%s", err)
+	}
+	return out, nil
+}
+
+func init() {
+	RegisterRPCMethod("govm_getSpending", rpcGetSpending)
+}
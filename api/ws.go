@@ -3,13 +3,13 @@ package api
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/govm-net/govm/conf"
 	"github.com/govm-net/govm/event"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/messages"
 	"github.com/govm-net/govm/runtime"
 	"github.com/govm-net/govm/wallet"
@@ -35,7 +35,6 @@ type hub struct {
 }
 
 func (h *hub) run() {
-	connLimit := conf.GetConf().OneConnPerMiner
 	var index int64
 	for {
 		select {
@@ -44,19 +43,20 @@ func (h *hub) run() {
 				break
 			}
 			index++
-			if !connLimit {
+			// read every time, it can be changed by reloading configure
+			if !conf.GetConf().OneConnPerMiner {
 				c.peer = fmt.Sprintf("id%d", index)
 			} else {
 				_, ok := h.clients[c.peer]
 				if ok {
-					log.Println("exist peer:", c.peer)
+					apiLog.Warn("exist peer", logger.KeyPeer, c.peer)
 					c.peer = ""
 					close(c.send)
 					break
 				}
 			}
 			stat.Add("ws_connect", 1)
-			log.Println("ws connect number:", len(h.clients), c.peer)
+			apiLog.Info("ws connect", "number", len(h.clients), logger.KeyPeer, c.peer)
 			h.clients[c.peer] = c
 			minerNum = len(h.clients)
 			m := messages.MinerInfo{}
@@ -185,7 +185,7 @@ func WSBlockForMining(ws *websocket.Conn) {
 	c.peer = fmt.Sprintf("c%d_k%x", chain, info.Address)
 	now := time.Now().Unix()
 	if info.Time > now+120 || now > info.Time+120 {
-		log.Println("error time")
+		apiLog.Warn("error time of miner", logger.KeyChain, chain, logger.KeyPeer, c.peer, "time", info.Time)
 		ws.Write([]byte("error time"))
 		return
 	}
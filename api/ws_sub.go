@@ -0,0 +1,322 @@
+package api
+
+import (
+	"encoding/hex"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/govm-net/govm/event"
+	"github.com/govm-net/govm/messages"
+	"golang.org/x/net/websocket"
+)
+
+// subscription type
+const (
+	SubNewHeads    = "new_heads"
+	SubTransaction = "transaction"
+	SubAppEvent    = "app_event"
+)
+
+const (
+	maxSubPerConn  = 100
+	maxKeysPerSub  = 1000
+	subSendBufSize = 100
+)
+
+// SubRequest the request of subscription,
+// Op:subscribe/unsubscribe,
+// Keys: the keys of transaction(hex), only for transaction,
+// App/Struct/Event: the filter of app event, empty means any
+type SubRequest struct {
+	ID           uint64   `json:"id,omitempty"`
+	Op           string   `json:"op,omitempty"`
+	Type         string   `json:"type,omitempty"`
+	Keys         []string `json:"keys,omitempty"`
+	App          string   `json:"app,omitempty"`
+	Struct       string   `json:"struct,omitempty"`
+	Event        string   `json:"event,omitempty"`
+	Subscription uint64   `json:"subscription,omitempty"`
+}
+
+// SubResponse the response of request(ID!=0) or the notification(ID==0)
+type SubResponse struct {
+	ID           uint64      `json:"id,omitempty"`
+	Subscription uint64      `json:"subscription,omitempty"`
+	Type         string      `json:"type,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	Data         interface{} `json:"data,omitempty"`
+}
+
+// HeadInfo the notification of new head
+type HeadInfo struct {
+	Chain    uint64 `json:"chain,omitempty"`
+	Index    uint64 `json:"index,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Time     uint64 `json:"time,omitempty"`
+	Producer string `json:"producer,omitempty"`
+	TransNum int    `json:"trans_num,omitempty"`
+}
+
+// TransConfirm the notification of transaction confirmed
+type TransConfirm struct {
+	Chain uint64 `json:"chain,omitempty"`
+	Key   string `json:"key,omitempty"`
+	Block string `json:"block,omitempty"`
+	Index uint64 `json:"index,omitempty"`
+}
+
+// AppEventInfo the notification of app event
+type AppEventInfo struct {
+	Chain  uint64   `json:"chain,omitempty"`
+	Block  string   `json:"block,omitempty"`
+	Index  uint64   `json:"index,omitempty"`
+	App    string   `json:"app,omitempty"`
+	Struct string   `json:"struct,omitempty"`
+	Event  string   `json:"event,omitempty"`
+	Params []string `json:"params,omitempty"`
+}
+
+type subscriber struct {
+	id    uint64
+	typ   string
+	keys  map[string]bool
+	app   string
+	sName string
+	event string
+}
+
+type subClient struct {
+	chain  uint64
+	send   chan *SubResponse
+	subs   map[uint64]*subscriber
+	closed bool
+}
+
+type subHub struct {
+	mu      sync.Mutex
+	clients map[*subClient]bool
+	id      uint64
+}
+
+var sh = subHub{clients: make(map[*subClient]bool)}
+
+func init() {
+	event.RegisterConsumer(func(m event.Message) error {
+		switch msg := m.(type) {
+		case *messages.NewHead:
+			sh.newHead(msg)
+		case *messages.BlockEvents:
+			sh.blockEvents(msg)
+		}
+		return nil
+	})
+}
+
+func (h *subHub) register(c *subClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[c] = true
+	stat.Add("ws_sub_connect", 1)
+}
+
+func (h *subHub) unregister(c *subClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closeClient(c)
+}
+
+// closeClient close the client, must be called with lock
+func (h *subHub) closeClient(c *subClient) {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	delete(h.clients, c)
+	close(c.send)
+}
+
+// push must be called with lock, close the client if it is too slow
+func (h *subHub) push(c *subClient, rsp *SubResponse) {
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- rsp:
+		stat.Add("ws_sub_send", 1)
+	default:
+		h.closeClient(c)
+	}
+}
+
+func (h *subHub) process(c *subClient, req SubRequest) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rsp := &SubResponse{ID: req.ID, Type: req.Type}
+	defer h.push(c, rsp)
+	switch req.Op {
+	case "subscribe":
+	case "unsubscribe":
+		if _, ok := c.subs[req.Subscription]; !ok {
+			rsp.Error = "not found the subscription"
+			return
+		}
+		delete(c.subs, req.Subscription)
+		rsp.Subscription = req.Subscription
+		return
+	default:
+		rsp.Error = "unknown op"
+		return
+	}
+	if len(c.subs) >= maxSubPerConn {
+		rsp.Error = "too many subscriptions"
+		return
+	}
+	s := &subscriber{typ: req.Type}
+	switch req.Type {
+	case SubNewHeads:
+	case SubTransaction:
+		if len(req.Keys) == 0 || len(req.Keys) > maxKeysPerSub {
+			rsp.Error = "error keys number"
+			return
+		}
+		s.keys = make(map[string]bool)
+		for _, k := range req.Keys {
+			key, err := hex.DecodeString(k)
+			if err != nil || len(key) == 0 {
+				rsp.Error = "error key,must hex string"
+				return
+			}
+			s.keys[hex.EncodeToString(key)] = true
+		}
+	case SubAppEvent:
+		if req.App != "" {
+			app, err := hex.DecodeString(req.App)
+			if err != nil {
+				rsp.Error = "error app,must hex string"
+				return
+			}
+			s.app = hex.EncodeToString(app)
+		}
+		s.sName = req.Struct
+		s.event = req.Event
+	default:
+		rsp.Error = "unknown type"
+		return
+	}
+	h.id++
+	s.id = h.id
+	c.subs[s.id] = s
+	rsp.Subscription = s.id
+}
+
+func (h *subHub) newHead(msg *messages.NewHead) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.clients) == 0 {
+		return
+	}
+	block := hex.EncodeToString(msg.Key)
+	head := HeadInfo{msg.Chain, msg.Index, block, msg.Time,
+		hex.EncodeToString(msg.Producer), len(msg.TransList)}
+	for c := range h.clients {
+		if c.chain != msg.Chain {
+			continue
+		}
+		for _, s := range c.subs {
+			switch s.typ {
+			case SubNewHeads:
+				h.push(c, &SubResponse{Subscription: s.id, Type: s.typ, Data: head})
+			case SubTransaction:
+				for _, k := range msg.TransList {
+					key := hex.EncodeToString(k)
+					if !s.keys[key] {
+						continue
+					}
+					delete(s.keys, key)
+					info := TransConfirm{msg.Chain, key, block, msg.Index}
+					h.push(c, &SubResponse{Subscription: s.id, Type: s.typ, Data: info})
+				}
+			}
+		}
+	}
+}
+
+// blockEvents push the app events of the processed block,
+// the events are pushed after the block is committed, not when they are emitted
+func (h *subHub) blockEvents(msg *messages.BlockEvents) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.clients) == 0 {
+		return
+	}
+	block := hex.EncodeToString(msg.Key)
+	for _, e := range msg.Events {
+		h.appEvent(msg.Chain, msg.Index, block, e)
+	}
+}
+
+func (h *subHub) appEvent(chain, index uint64, block string, msg *messages.AppEvent) {
+	app := hex.EncodeToString(msg.App)
+	info := AppEventInfo{chain, block, index, app, msg.Struct, msg.Event, nil}
+	for _, p := range msg.Params {
+		info.Params = append(info.Params, hex.EncodeToString(p))
+	}
+	for c := range h.clients {
+		if c.chain != chain {
+			continue
+		}
+		for _, s := range c.subs {
+			if s.typ != SubAppEvent {
+				continue
+			}
+			if s.app != "" && s.app != app {
+				continue
+			}
+			if s.sName != "" && s.sName != msg.Struct {
+				continue
+			}
+			if s.event != "" && s.event != msg.Event {
+				continue
+			}
+			h.push(c, &SubResponse{Subscription: s.id, Type: s.typ, Data: info})
+		}
+	}
+}
+
+// WSSubscribe subscribe new heads, transaction confirmations and app events of the chain
+func WSSubscribe(ws *websocket.Conn) {
+	defer ws.Close()
+	vars := mux.Vars(ws.Request())
+	chain, err := strconv.ParseUint(vars["chain"], 10, 64)
+	if err != nil || chain == 0 {
+		websocket.JSON.Send(ws, SubResponse{Error: "error chain"})
+		return
+	}
+	c := &subClient{
+		chain: chain,
+		send:  make(chan *SubResponse, subSendBufSize),
+		subs:  make(map[uint64]*subscriber),
+	}
+	sh.register(c)
+
+	go func() {
+		for {
+			var req SubRequest
+			err := websocket.JSON.Receive(ws, &req)
+			if err != nil {
+				break
+			}
+			sh.process(c, req)
+		}
+		sh.unregister(c)
+	}()
+
+	for rsp := range c.send {
+		err := websocket.JSON.Send(ws, rsp)
+		if err != nil {
+			break
+		}
+	}
+	sh.unregister(c)
+}
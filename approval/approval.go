@@ -0,0 +1,84 @@
+// Package approval approve the transactions(and sign) by the wallet of node before they are created,
+// the methods: console(the identifying code of stdin), policy, webhook, totp
+package approval
+
+import (
+	"errors"
+	"fmt"
+)
+
+// the ops of Request, it is the same as the ops of govm-tx, and sign/mine
+const (
+	OpsTransfer = "transfer"
+	OpsMove     = "move"
+	OpsNewChain = "new_chain"
+	OpsNewApp   = "new_app"
+	OpsRunApp   = "run_app"
+	OpsAppLife  = "app_life"
+	OpsMiner    = "miner"
+	OpsAdmin    = "admin"
+	OpsSign     = "sign"
+	OpsMine     = "mine"
+)
+
+// AllOps all the ops of Request
+var AllOps = []string{OpsTransfer, OpsMove, OpsNewChain, OpsNewApp, OpsRunApp,
+	OpsAppLife, OpsMiner, OpsAdmin, OpsSign, OpsMine}
+
+// Request the request waiting for approval
+type Request struct {
+	Ops    string `json:"ops"`
+	Chain  uint64 `json:"chain,omitempty"`
+	From   string `json:"from,omitempty"`
+	Peer   string `json:"peer,omitempty"`
+	Cost   uint64 `json:"cost,omitempty"`
+	Energy uint64 `json:"energy,omitempty"`
+	// Caller the name of api key
+	Caller string `json:"caller,omitempty"`
+	// Detail the body of the api request
+	Detail string `json:"detail,omitempty"`
+	// Code the code of totp, it is not sent to webhook
+	Code string `json:"-"`
+}
+
+// Approver approve the request, return error if it is rejected
+type Approver interface {
+	Approve(req Request) error
+}
+
+// Canceler the approver which reserves something(such as the allowance of spending) in Approve,
+// Cancel is called if the request is rejected by other approver
+type Canceler interface {
+	Cancel(req Request)
+}
+
+// ErrRejected the request is rejected
+var ErrRejected = errors.New("rejected")
+
+// Approvers all the approvers must approve the request
+type Approvers []Approver
+
+// Approve approve the request by every approver in order
+func (list Approvers) Approve(req Request) error {
+	for i, it := range list {
+		if err := it.Approve(req); err != nil {
+			for _, a := range list[:i] {
+				if c, ok := a.(Canceler); ok {
+					c.Cancel(req)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Reject reject all requests
+type Reject struct {
+	Reason string
+}
+
+// Approve reject the request
+func (r Reject) Approve(req Request) error {
+	return fmt.Errorf("%w: %s", ErrRejected, r.Reason)
+}
@@ -0,0 +1,114 @@
+package approval
+
+import (
+	"encoding/base32"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTOTP(t *testing.T) {
+	// RFC 4226 Appendix D
+	key := []byte("12345678901234567890")
+	for i, hope := range []string{"755224", "287082", "359152"} {
+		if code := totpCode(key, uint64(i)); code != hope {
+			t.Errorf("error code of %d,hope:%s,get:%s", i, hope, code)
+		}
+	}
+	a, err := NewTOTP(base32.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.now = func() time.Time { return time.Unix(59, 0) }
+	if err = a.Approve(Request{Ops: OpsTransfer, Code: "000000"}); !errors.Is(err, ErrRejected) {
+		t.Error("hope rejected:", err)
+	}
+	if err = a.Approve(Request{Ops: OpsTransfer, Code: "287082"}); err != nil {
+		t.Error("hope approved:", err)
+	}
+	if err = a.Approve(Request{Ops: OpsTransfer, Code: "287082"}); err == nil {
+		t.Error("the code is used twice")
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	p := NewPolicy(100)
+	if err := p.Approve(Request{Ops: OpsSign}); err == nil {
+		t.Error("hope sign rejected")
+	}
+	if err := p.Approve(Request{Ops: OpsTransfer, Cost: 60, Energy: 10}); err != nil {
+		t.Error("hope approved:", err)
+	}
+	if err := NewPolicy(0).Approve(Request{Ops: OpsTransfer, Cost: 1}); err == nil {
+		t.Error("hope rejected without daily limit")
+	}
+}
+
+type testSpending struct {
+	s     *Spending
+	limit uint64
+}
+
+func (a testSpending) Approve(req Request) error {
+	amount, err := req.Amount()
+	if err != nil {
+		return err
+	}
+	return a.s.Reserve(req.From, amount, a.limit)
+}
+
+func (a testSpending) Cancel(req Request) {
+	amount, _ := req.Amount()
+	a.s.Release(req.From, amount)
+}
+
+func TestSpending(t *testing.T) {
+	from := "from_test_spending"
+	a := testSpending{new(Spending), 100}
+	if err := a.Approve(Request{From: from, Cost: 60, Energy: 10}); err != nil {
+		t.Error("hope approved:", err)
+	}
+	if err := a.Approve(Request{From: from, Cost: 40}); err == nil {
+		t.Error("hope over daily limit")
+	}
+
+	// rejected by the next approver, the spending is released
+	list := Approvers{a, Reject{"test"}}
+	if err := list.Approve(Request{From: from, Cost: 30}); err == nil {
+		t.Error("hope rejected")
+	}
+	if a.s.Spent(from) != 70 {
+		t.Error("error spent:", a.s.Spent(from))
+	}
+}
+
+type memStore map[string]uint64
+
+func (m memStore) Load(day, from string) uint64 {
+	return m[day+from]
+}
+
+func (m memStore) Save(day, from string, spent uint64) {
+	m[day+from] = spent
+}
+
+func TestSpendingStore(t *testing.T) {
+	from := "from_test_store"
+	store := make(memStore)
+	s := &Spending{Store: store}
+	if err := s.Reserve(from, 60, 100); err != nil {
+		t.Fatal(err)
+	}
+	// restart
+	s = &Spending{Store: store}
+	if s.Spent(from) != 60 {
+		t.Error("error spent after restart:", s.Spent(from))
+	}
+	if err := s.Reserve(from, 50, 100); err == nil {
+		t.Error("hope over daily limit after restart")
+	}
+	s.Release(from, 10)
+	if store[today()+from] != 50 {
+		t.Error("error saved spending:", store[today()+from])
+	}
+}
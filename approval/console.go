@@ -0,0 +1,43 @@
+package approval
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Console the identifying code of console, it prints a random code to stdout and waits for it from input
+type Console struct {
+	input   chan string
+	timeout time.Duration
+}
+
+// NewConsole new console approver, input is the lines of stdin
+func NewConsole(input chan string) *Console {
+	return &Console{input: input, timeout: 30 * time.Second}
+}
+
+// Approve print the identifying code and wait for it
+func (c *Console) Approve(req Request) error {
+	//clean inputString if exist
+	select {
+	case <-c.input:
+	default:
+	}
+	str := fmt.Sprintf("%06d", rand.Int63()%1000000)
+	fmt.Println("Identify:", req.Ops, req.Chain, req.Detail)
+	fmt.Println("Input Identifying Code:")
+	fmt.Println(str)
+	var in string
+	select {
+	case <-time.After(c.timeout):
+		fmt.Println("identify:input timeout(30second)")
+		return fmt.Errorf("identify:timeout")
+	case in = <-c.input:
+	}
+	if in != str {
+		fmt.Printf("error identifying code,hope:%s,get:%s\n", str, in)
+		return fmt.Errorf("error indentifying code")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package approval
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpendingStore the storage of the spending, the spending of today is loaded from it after restart
+type SpendingStore interface {
+	Load(day, from string) uint64
+	Save(day, from string, spent uint64)
+}
+
+// Spending the daily spending of every account, it is reset at 00:00(UTC)
+type Spending struct {
+	mu    sync.Mutex
+	day   string
+	spent map[string]uint64
+	// Store save the spending, it is only kept in memory(reset by restart) if nil
+	Store SpendingStore
+}
+
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+func (s *Spending) reset() {
+	if d := today(); d != s.day || s.spent == nil {
+		s.spent = make(map[string]uint64)
+		s.day = d
+	}
+}
+
+// get the spending of the account today, must be called with mu
+func (s *Spending) get(from string) uint64 {
+	s.reset()
+	v, ok := s.spent[from]
+	if !ok && s.Store != nil {
+		v = s.Store.Load(s.day, from)
+		s.spent[from] = v
+	}
+	return v
+}
+
+func (s *Spending) set(from string, spent uint64) {
+	s.spent[from] = spent
+	if s.Store != nil {
+		s.Store.Save(s.day, from, spent)
+	}
+}
+
+// Reserve add the amount to the spending of the account, return error if it is over the limit
+func (s *Spending) Reserve(from string, amount, limit uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	used := s.get(from)
+	if used+amount < used || used+amount > limit {
+		return fmt.Errorf("over daily limit,limit:%d,spent:%d,hope:%d", limit, used, amount)
+	}
+	s.set(from, used+amount)
+	return nil
+}
+
+// Release release the amount reserved by Reserve
+func (s *Spending) Release(from string, amount uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.day != today() {
+		return
+	}
+	used := s.get(from)
+	if used < amount {
+		return
+	}
+	s.set(from, used-amount)
+}
+
+// Spent return the spending of the account today
+func (s *Spending) Spent(from string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(from)
+}
+
+// Amount the spending(cost+energy) of the request
+func (req Request) Amount() (uint64, error) {
+	amount := req.Cost + req.Energy
+	if amount < req.Cost {
+		return 0, fmt.Errorf("error amount,cost:%d,energy:%d", req.Cost, req.Energy)
+	}
+	return amount, nil
+}
+
+// Policy approve the request automatically, the limits(daily limit, payees and so on) are checked by the
+// spending policy of node(spending of conf.json) before it, so the policy only approves the limited spending.
+// the sign of message is rejected, it can sign any transaction.
+type Policy struct {
+	dailyLimit uint64
+}
+
+// NewPolicy new policy, dailyLimit is the daily limit of spending policy, all requests are rejected if it is 0
+func NewPolicy(dailyLimit uint64) *Policy {
+	return &Policy{dailyLimit: dailyLimit}
+}
+
+// NewSet new set of the strings(lower case), return nil if list is empty
+func NewSet(list []string) map[string]bool {
+	if len(list) == 0 {
+		return nil
+	}
+	out := make(map[string]bool)
+	for _, it := range list {
+		out[strings.ToLower(it)] = true
+	}
+	return out
+}
+
+// Approve approve the request if it is not sign and the spending is limited
+func (p *Policy) Approve(req Request) error {
+	if req.Ops == OpsSign {
+		return fmt.Errorf("%w by policy: sign message", ErrRejected)
+	}
+	if p.dailyLimit == 0 {
+		return fmt.Errorf("%w by policy: no daily limit of spending", ErrRejected)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package approval
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the parameters of totp(RFC 6238), they are the default values of the authenticator apps
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// the codes of the previous and next period are accepted
+	totpSkew = 1
+)
+
+// TOTP approve the request with the code of totp(such as google authenticator),
+// the code is sent by the request(header X-Approval-Code), every code can be used once
+type TOTP struct {
+	key []byte
+	now func() time.Time
+}
+
+// the last used counter of every key, it is shared by all TOTP(the TOTP is new after reload configure)
+var (
+	totpMu   sync.Mutex
+	lastUsed = make(map[string]uint64)
+)
+
+// NewTOTP new totp approver, the secret is base32(no padding is ok)
+func NewTOTP(secret string) (*TOTP, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("error totp secret,%v", err)
+	}
+	return &TOTP{key: key, now: time.Now}, nil
+}
+
+// totpCode the code of the counter
+func totpCode(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	h := hmac.New(sha1.New, key)
+	h.Write(msg[:])
+	sum := h.Sum(nil)
+	offset := sum[len(sum)-1] & 0xf
+	v := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, v%mod)
+}
+
+// Approve check the code of the request
+func (t *TOTP) Approve(req Request) error {
+	if req.Code == "" {
+		return fmt.Errorf("%w by totp: need code(header X-Approval-Code)", ErrRejected)
+	}
+	counter := uint64(t.now().Unix() / totpPeriod)
+	totpMu.Lock()
+	defer totpMu.Unlock()
+	k := string(t.key)
+	for i := counter - totpSkew; i <= counter+totpSkew; i++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(t.key, i)), []byte(req.Code)) != 1 {
+			continue
+		}
+		if last, ok := lastUsed[k]; ok && i <= last {
+			return fmt.Errorf("%w by totp: the code is used", ErrRejected)
+		}
+		lastUsed[k] = i
+		return nil
+	}
+	return fmt.Errorf("%w by totp: error code", ErrRejected)
+}
@@ -0,0 +1,56 @@
+package approval
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// WebhookResponse the response of the approval service
+type WebhookResponse struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Webhook post the request(json) to the approval service(such as a local service asking the owner),
+// the request is approved if the status is 200 and the response is {"approved":true}
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook new webhook approver, timeout is second, default 30
+func NewWebhook(url string, timeout int) *Webhook {
+	if timeout <= 0 {
+		timeout = 30
+	}
+	return &Webhook{url: url, client: &http.Client{Timeout: time.Duration(timeout) * time.Second}}
+}
+
+// Approve ask the approval service
+func (h *Webhook) Approve(req Request) error {
+	data, _ := json.Marshal(req)
+	resp, err := h.client.Post(h.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("fail to post webhook,%s", err)
+	}
+	defer resp.Body.Close()
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("fail to read response of webhook,%s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w by webhook: status %s", ErrRejected, resp.Status)
+	}
+	var rst WebhookResponse
+	if err = json.Unmarshal(data, &rst); err != nil {
+		return fmt.Errorf("error response of webhook,%s", err)
+	}
+	if !rst.Approved {
+		return fmt.Errorf("%w by webhook: %s", ErrRejected, rst.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/govm-net/govm/apptest"
+)
+
+// render the core.tmpl to core.go, run by go generate
+func main() {
+	tmpl, err := ioutil.ReadFile("../../core/core.tmpl")
+	if err != nil {
+		fmt.Println("fail to read core.tmpl:", err)
+		os.Exit(2)
+	}
+	code, err := apptest.RenderCore(tmpl)
+	if err != nil {
+		fmt.Println("fail to render core.tmpl:", err)
+		os.Exit(2)
+	}
+	err = ioutil.WriteFile("core.go", code, 0666)
+	if err != nil {
+		fmt.Println("fail to write core.go:", err)
+		os.Exit(2)
+	}
+}
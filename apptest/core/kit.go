@@ -0,0 +1,89 @@
+package zff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
+
+//go:generate go run gen.go
+
+import (
+	"time"
+
+	"github.com/govm-net/govm/apptest"
+)
+
+var gRuntime *apptest.Runtime
+
+// Reset clear all data of core and use a new in-memory runtime,
+// the block is on chain 1 and the time of block is now
+func Reset() *apptest.Runtime {
+	gRuntime = apptest.NewRuntime(1)
+	gBS.iRuntime = gRuntime
+	info := BaseInfo{}
+	info.Chain = 1
+	info.ID = 1
+	info.Time = uint64(time.Now().UnixNano()) / uint64(time.Millisecond)
+	info.BaseOpsEnergy = getBaseOpsEnergy(info.Chain)
+	SetBaseInfo(info)
+	return gRuntime
+}
+
+// GetRuntime return the in-memory runtime
+func GetRuntime() *apptest.Runtime {
+	return gRuntime
+}
+
+func getBaseOpsEnergy(chain uint64) uint64 {
+	var out uint64 = 1000
+	for chain > 0 {
+		chain = chain / 2
+		out = out * 15 / 16
+	}
+	return out + 1
+}
+
+// SetBaseInfo set the info of the last block, such as Time, Chain and BaseOpsEnergy.
+// the data of test is not consumed energy
+func SetBaseInfo(info BaseInfo) {
+	assert(info.Chain > 0)
+	gRuntime.Chain = info.Chain
+	gBS.BaseInfo = info
+	gBS.DbSet(gBS.pDbStat.owner, []byte{StatBaseInfo}, gBS.Encode(0, info), maxDbLife)
+}
+
+// SetBalance set the balance of the address
+func SetBalance(addr Address, value uint64) {
+	if value == 0 {
+		gBS.DbSet(gBS.pDbCoin.owner, addr[:], nil, 0)
+		return
+	}
+	gBS.DbSet(gBS.pDbCoin.owner, addr[:], gBS.Encode(0, value), maxDbLife+gBS.Time)
+}
+
+// GetBalance get the balance of the address
+func GetBalance(addr Address) uint64 {
+	v, life := gBS.DbGet(gBS.pDbCoin.owner, addr[:])
+	if len(v) == 0 || life <= gBS.Time {
+		return 0
+	}
+	var out uint64
+	gBS.Decode(0, v, &out)
+	return out
+}
+
+// SetAppBalance set the balance of app by the private struct of app,
+// the account of app is the public address if the app not exist, return the account
+func SetAppBalance(owner interface{}, value uint64) Address {
+	name := Hash{}
+	gBS.Decode(0, gBS.GetAppName(owner), &name)
+	assert(!name.Empty())
+	info := AppInfo{}
+	v, _ := gBS.DbGet(gBS.pDbApp.owner, name[:])
+	if len(v) > 0 {
+		gBS.Decode(0, v, &info)
+	} else {
+		gBS.Decode(0, name[:], &info.Account)
+		info.Account[0] = prefixOfPlublcAddr
+		info.Life = TimeYear + gBS.Time
+		info.Flag = AppFlagRun | AppFlagPlublc
+		gBS.DbSet(gBS.pDbApp.owner, name[:], gBS.Encode(0, info), info.Life)
+	}
+	SetBalance(info.Account, value)
+	return info.Account
+}
@@ -0,0 +1,40 @@
+package zff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
+
+import (
+	"testing"
+)
+
+type tTestApp struct{}
+
+func TestTransferAccounts(t *testing.T) {
+	rt := Reset()
+	user := Address{1, 2, 3}
+	account := SetAppBalance(tTestApp{}, 1000)
+	rt.ResetCounter()
+
+	TransferAccounts(tTestApp{}, user, 300)
+	if GetBalance(account) != 700 || GetBalance(user) != 300 {
+		t.Errorf("error balance,app:%d,user:%d", GetBalance(account), GetBalance(user))
+	}
+	if len(rt.FindEvents("pTransfer")) != 1 {
+		t.Errorf("error events:%v", rt.Events())
+	}
+	if rt.EnergyUsed() == 0 {
+		t.Error("not consume energy")
+	}
+
+	db := GetDB(tTestApp{})
+	db.Set([]byte("key"), []byte("value"), TimeHour)
+	v, life := db.Get([]byte("key"))
+	if string(v) != "value" || life != TimeHour {
+		t.Errorf("error db data:%s,%d", v, life)
+	}
+
+	rt.EnergyLimit = rt.EnergyUsed() + 1
+	defer func() {
+		if recover() == nil {
+			t.Error("hope panic when the energy is not enough")
+		}
+	}()
+	TransferAccounts(tTestApp{}, Address{4, 5, 6}, 1)
+}
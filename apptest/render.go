@@ -0,0 +1,37 @@
+package apptest
+
+import (
+	"bytes"
+	"errors"
+	"go/format"
+)
+
+const (
+	coreImport = "import (\n\t\"encoding/json\"\n\t\"os\"\n\n\t\"github.com/govm-net/govm/runtime\"\n)\n"
+	kitImport  = "import (\n\t\"encoding/json\"\n)\n"
+	initStart  = "\n\trunt := runtime.NewRuntime(\"\", \"\")"
+	initEnd    = "\n// GetHash get data hash"
+	kitHeader  = "// Code generated by apptest/core/gen.go from core/core.tmpl. DO NOT EDIT.\n\n"
+)
+
+// RenderCore render the core.tmpl to the core of apptest,
+// the runtime of node is replaced by the in-memory runtime(see apptest/core/kit.go)
+func RenderCore(tmpl []byte) ([]byte, error) {
+	if bytes.Count(tmpl, []byte(coreImport)) != 1 {
+		return nil, errors.New("not found the import of core.tmpl")
+	}
+	out := bytes.Replace(tmpl, []byte(coreImport), []byte(kitImport), 1)
+
+	start := bytes.Index(out, []byte(initStart))
+	end := bytes.Index(out, []byte(initEnd))
+	if start < 0 || end < start {
+		return nil, errors.New("not found the runtime of core.tmpl")
+	}
+	code := append([]byte(kitHeader), out[:start]...)
+	code = append(code, []byte("\n\tReset()\n}\n")...)
+	code = append(code, out[end:]...)
+	if bytes.Contains(code, []byte("{{")) || bytes.Contains(code, []byte("runtime.")) {
+		return nil, errors.New("unknown template parameter or runtime of core.tmpl")
+	}
+	return format.Source(code)
+}
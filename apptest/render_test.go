@@ -0,0 +1,25 @@
+package apptest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestRenderCore(t *testing.T) {
+	tmpl, err := ioutil.ReadFile("../core/core.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := RenderCore(tmpl)
+	if err != nil {
+		t.Fatal("fail to render core.tmpl:", err)
+	}
+	old, err := ioutil.ReadFile("./core/core.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(code, old) != 0 {
+		t.Error("apptest/core/core.go is out of date, run go generate in apptest/core")
+	}
+}
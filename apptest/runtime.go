@@ -0,0 +1,296 @@
+// Package apptest the in-memory runtime of core, used to test the app without node and database.
+//
+// the app imports github.com/govm-net/govm/apptest/core instead of the core of chain in the test,
+// the package name of app must be "a"+hex(app name), such as:
+//
+//	rt := core.Reset()
+//	core.SetBalance(user, 1000)
+//	run(user[:], in, 10)
+//	rt.EnergyUsed(), rt.Events(), core.GetBalance(user)
+package apptest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/govm-net/govm/wallet"
+)
+
+const (
+	startOfDB  = 'd'
+	startOfLog = 'l'
+)
+
+// encoding type
+const (
+	EncBinary = uint8(iota)
+	EncJSON
+	EncGob
+)
+
+// Event the event emitted by app or core
+type Event struct {
+	User   string
+	Name   string
+	Params [][]byte
+}
+
+// AppCall the record of RunApp
+type AppCall struct {
+	Name   []byte
+	User   []byte
+	Data   []byte
+	Energy uint64
+	Cost   uint64
+}
+
+// AppFunc the run function of app
+type AppFunc func(user, in []byte, cost uint64)
+
+// Runtime in-memory runtime, implement the iRuntime of core
+type Runtime struct {
+	Chain uint64
+	// EnergyLimit panic if the energy used is more than the limit, no limit if it is 0
+	EnergyLimit uint64
+	energy      uint64
+	dbData      map[string][]byte
+	logData     map[string][]byte
+	events      []Event
+	calls       []AppCall
+	newApps     map[string][]byte
+	apps        map[string]AppFunc
+}
+
+// NewRuntime new in-memory runtime of the chain
+func NewRuntime(chain uint64) *Runtime {
+	out := new(Runtime)
+	out.Chain = chain
+	out.dbData = make(map[string][]byte)
+	out.logData = make(map[string][]byte)
+	out.newApps = make(map[string][]byte)
+	out.apps = make(map[string]AppFunc)
+	return out
+}
+
+func structName(owner interface{}, prefix byte) string {
+	if reflect.ValueOf(owner).Kind() != reflect.Struct {
+		panic(fmt.Sprintf("the owner must be struct:%T", owner))
+	}
+	typ := reflect.TypeOf(owner).String()
+	typeSplic := strings.Split(typ, ".")
+	if len(typeSplic) != 2 {
+		panic(typ)
+	}
+	startChar := typeSplic[1][0]
+	if startChar < 'a' || startChar > 'z' {
+		panic(typ)
+	}
+	out := []byte(typ)
+	out[0] = prefix
+	return string(out)
+}
+
+func (r *Runtime) dbKey(owner interface{}, key []byte) string {
+	return fmt.Sprintf("%s_%x", structName(owner, startOfDB), key)
+}
+
+func (r *Runtime) logKey(owner interface{}, chain uint64, key []byte) string {
+	return fmt.Sprintf("%d_%s_%x", chain, structName(owner, startOfLog), key)
+}
+
+func (r *Runtime) splitLife(data []byte) ([]byte, uint64) {
+	if len(data) < 8 {
+		return nil, 0
+	}
+	n := len(data)
+	return data[:n-8], binary.BigEndian.Uint64(data[n-8:])
+}
+
+// GetHash get the hash of the data
+func (r *Runtime) GetHash(in []byte) []byte {
+	return wallet.GetHash(in)
+}
+
+// Encode encode the data, typ:EncBinary,EncJSON,EncGob
+func (r *Runtime) Encode(typ uint8, in interface{}) []byte {
+	var err error
+	buf := new(bytes.Buffer)
+	switch typ {
+	case EncBinary:
+		err = binary.Write(buf, binary.BigEndian, in)
+	case EncJSON:
+		var out []byte
+		out, err = json.Marshal(in)
+		buf.Write(out)
+	case EncGob:
+		err = gob.NewEncoder(buf).Encode(in)
+	default:
+		panic("not support encode type")
+	}
+	if err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+// Decode decode the data, return the length of used data
+func (r *Runtime) Decode(typ uint8, in []byte, out interface{}) int {
+	var err error
+	buf := bytes.NewReader(in)
+	switch typ {
+	case EncBinary:
+		err = binary.Read(buf, binary.BigEndian, out)
+	case EncJSON:
+		err = json.Unmarshal(in, out)
+		buf.Reset(nil)
+	case EncGob:
+		err = gob.NewDecoder(buf).Decode(out)
+	default:
+		panic("not support decode type")
+	}
+	if err != nil {
+		panic(err)
+	}
+	return len(in) - buf.Len()
+}
+
+// Recover verify the sign
+func (r *Runtime) Recover(address, sign, msg []byte) bool {
+	return wallet.Recover(address, sign, msg)
+}
+
+// DbSet write the db, the life is the absolute time
+func (r *Runtime) DbSet(owner interface{}, key, value []byte, life uint64) {
+	k := r.dbKey(owner, key)
+	if len(value) == 0 {
+		delete(r.dbData, k)
+		return
+	}
+	v := make([]byte, len(value), len(value)+8)
+	copy(v, value)
+	r.dbData[k] = append(v, r.Encode(0, life)...)
+}
+
+// DbGet read the db, return the value and the absolute life
+func (r *Runtime) DbGet(owner interface{}, key []byte) ([]byte, uint64) {
+	return r.splitLife(r.dbData[r.dbKey(owner, key)])
+}
+
+// DbGetLife get life of the db data
+func (r *Runtime) DbGetLife(owner interface{}, key []byte) uint64 {
+	_, life := r.DbGet(owner, key)
+	return life
+}
+
+// LogWrite write the log of current chain
+func (r *Runtime) LogWrite(owner interface{}, key, value []byte, life uint64) {
+	v := make([]byte, len(value), len(value)+8)
+	copy(v, value)
+	r.logData[r.logKey(owner, r.Chain, key)] = append(v, r.Encode(0, life)...)
+}
+
+// LogRead read the log of the chain
+func (r *Runtime) LogRead(owner interface{}, chain uint64, key []byte) ([]byte, uint64) {
+	return r.splitLife(r.logData[r.logKey(owner, chain, key)])
+}
+
+// LogReadLife get life of the log of current chain
+func (r *Runtime) LogReadLife(owner interface{}, key []byte) uint64 {
+	_, life := r.LogRead(owner, r.Chain, key)
+	return life
+}
+
+// SetLog write the log of other chain, used to prepare the data of test
+func (r *Runtime) SetLog(owner interface{}, chain uint64, key, value []byte, life uint64) {
+	v := make([]byte, len(value), len(value)+8)
+	copy(v, value)
+	r.logData[r.logKey(owner, chain, key)] = append(v, r.Encode(0, life)...)
+}
+
+// GetAppName get the app name by the private struct of app, the package name is "a"+hex(app name)
+func (r *Runtime) GetAppName(owner interface{}) []byte {
+	name := structName(owner, startOfDB)[1:]
+	appName, _ := hex.DecodeString(strings.Split(name, ".")[0])
+	return appName
+}
+
+// NewApp record the code of new app
+func (r *Runtime) NewApp(name []byte, code []byte) {
+	r.newApps[hex.EncodeToString(name)] = code
+}
+
+// RegisterApp register the run function of other app, it is called by RunApp
+func (r *Runtime) RegisterApp(name []byte, f AppFunc) {
+	r.apps[hex.EncodeToString(name)] = f
+}
+
+// RunApp record the call and run the registered app, panic if the app is not registered
+func (r *Runtime) RunApp(name, user, data []byte, energy, cost uint64) {
+	r.calls = append(r.calls, AppCall{name, user, data, energy, cost})
+	f := r.apps[hex.EncodeToString(name)]
+	if f == nil {
+		panic(fmt.Sprintf("not register the app:%x", name))
+	}
+	f(user, data, cost)
+}
+
+// Event record the event
+func (r *Runtime) Event(user interface{}, event string, param ...[]byte) {
+	e := Event{User: fmt.Sprintf("%T", user), Name: event}
+	for _, it := range param {
+		e.Params = append(e.Params, append([]byte{}, it...))
+	}
+	r.events = append(r.events, e)
+}
+
+// ConsumeEnergy consume energy, panic if it is more than the EnergyLimit
+func (r *Runtime) ConsumeEnergy(energy uint64) {
+	r.energy += energy
+	if r.energy < energy || (r.EnergyLimit > 0 && r.energy > r.EnergyLimit) {
+		panic(fmt.Sprintf("energy not enough,limit:%d,used:%d", r.EnergyLimit, r.energy))
+	}
+}
+
+// EnergyUsed return the energy consumed since the last ResetCounter
+func (r *Runtime) EnergyUsed() uint64 {
+	return r.energy
+}
+
+// Events return the events emitted since the last ResetCounter
+func (r *Runtime) Events() []Event {
+	return r.events
+}
+
+// FindEvents return the events with the name
+func (r *Runtime) FindEvents(name string) []Event {
+	var out []Event
+	for _, it := range r.events {
+		if it.Name == name {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// AppCalls return the calls of RunApp since the last ResetCounter
+func (r *Runtime) AppCalls() []AppCall {
+	return r.calls
+}
+
+// NewApps return the code of new apps, the key is hex(app name)
+func (r *Runtime) NewApps() map[string][]byte {
+	return r.newApps
+}
+
+// ResetCounter reset the energy, events and calls, the data of db and log are kept
+func (r *Runtime) ResetCounter() {
+	r.energy = 0
+	r.events = nil
+	r.calls = nil
+}
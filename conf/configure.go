@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/govm-net/govm/wallet"
@@ -19,12 +20,15 @@ type TConfig struct {
 	HTTPPort        int    `json:"http_port,omitempty"`
 	DbAddrType      string `json:"db_addr_type,omitempty"`
 	DbServerAddr    string `json:"db_server_addr,omitempty"`
+	DbType          string `json:"db_type,omitempty"`
+	DbDir           string `json:"db_dir,omitempty"`
 	CorePackName    []byte `json:"core_pack_name,omitempty"`
 	WalletAddr      []byte `json:"wallet_addr,omitempty"`
 	SignPrefix      []byte `json:"sign_prefix,omitempty"`
 	PrivateKey      []byte `json:"private_key,omitempty"`
 	Password        string `json:"password,omitempty"`
 	WalletFile      string `json:"wallet_file,omitempty"`
+	KeyStoreFile    string `json:"keystore_file,omitempty"`
 	SaveLog         bool   `json:"save_log,omitempty"`
 	IdentifyingCode bool   `json:"identifying_code,omitempty"`
 	TrustedServer   string `json:"trusted_server,omitempty"`
@@ -38,10 +42,86 @@ type TConfig struct {
 	SafeEnvironment bool   `json:"safe_environment,omitempty"`
 	PProfAddr       string `json:"pprof_addr,omitempty"`
 	RestfulLog      bool   `json:"restful_log,omitempty"`
+	// LogLevel the default level of log(debug,info,warn,error,off), default info
+	LogLevel string `json:"log_level,omitempty"`
+	// LogLevels the level of log of the subsystems(sync,mining,runtime,api,p2p,core,node)
+	LogLevels map[string]string `json:"log_levels,omitempty"`
+	// DenyDefaultPassword refuse to start if the password is the default password
+	DenyDefaultPassword bool `json:"deny_default_password,omitempty"`
+	// MempoolSize the max number of transactions waiting for mining of every chain
+	MempoolSize int `json:"mempool_size,omitempty"`
+	// MempoolMaxAge the max age(minutes) of transactions waiting for mining
+	MempoolMaxAge uint64 `json:"mempool_max_age,omitempty"`
+	// Devnet the local devnet mode, no network bootstrap, the blocks are produced on demand
+	Devnet bool `json:"devnet,omitempty"`
+	// GenesisFile the genesis file of private network(handler.GenesisSpec), it is empty for the main net.
+	// the hash of genesis is bound to the net id, the nodes with different genesis can not connect each other
+	GenesisFile string `json:"genesis_file,omitempty"`
+	// DevnetAutoMine produce a block on each transaction of devnet
+	DevnetAutoMine bool `json:"devnet_auto_mine,omitempty"`
+	// AdminToken the bearer token of the admin api(such as reload configure), it is the api key with admin scope
+	AdminToken string `json:"admin_token,omitempty"`
+	// APIKeys the keys of api, all apis are open if it is empty and AdminToken is empty
+	APIKeys []APIKey `json:"api_keys,omitempty"`
+	// TLSCertFile TLSKeyFile the certificate and key of https, it is http if they are empty
+	TLSCertFile string `json:"tls_cert_file,omitempty"`
+	TLSKeyFile  string `json:"tls_key_file,omitempty"`
+	// AuditLog the file of audit log(the state-changing requests), default ./log/audit.log
+	AuditLog string `json:"audit_log,omitempty"`
+	// Approval the approval of the transactions(and sign) by the wallet of node
+	Approval ApprovalConf `json:"approval,omitempty"`
+	// Spending the spending policy of the wallet of node, it is checked before approval(also devnet)
+	Spending SpendingConf `json:"spending,omitempty"`
 }
 
+// SpendingConf the spending policy of the wallet of node, the empty field is unlimited
+type SpendingConf struct {
+	// DailyLimit the max spending(cost+energy, t9) of every account per day(UTC),
+	// the spending is saved in db_dir/spending.db, it is not reset by restart
+	DailyLimit uint64 `json:"daily_limit,omitempty"`
+	// TransLimit the max spending(cost+energy, t9) of every transaction
+	TransLimit uint64 `json:"trans_limit,omitempty"`
+	// Payees the allowed payees(hex address) of transfer
+	Payees []string `json:"payees,omitempty"`
+	// Chains the allowed chains
+	Chains []uint64 `json:"chains,omitempty"`
+	// Ops the allowed ops: transfer, move, new_chain, new_app, run_app, app_life, miner, admin, sign, mine.
+	// sign is rejected if any limit is set and it is not in Ops, the signed message may be a transaction
+	Ops []string `json:"ops,omitempty"`
+}
+
+// ApprovalConf the configure of approval, see package approval
+type ApprovalConf struct {
+	// Methods all the methods must approve: console, policy, webhook, totp.
+	// default: console if identifying_code or safe_environment, otherwise reject.
+	// policy approves the transactions allowed by spending automatically, spending.daily_limit is required.
+	// the limits(daily_limit, payees...) are only configured by spending, they apply to all methods
+	Methods []string `json:"methods,omitempty"`
+	// WebhookURL webhook: the url of approval service, the request is posted as json
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// WebhookTimeout webhook: timeout(second), default 30
+	WebhookTimeout int `json:"webhook_timeout,omitempty"`
+	// TOTPSecret totp: the secret(base32), the code is sent by the header X-Approval-Code
+	TOTPSecret string `json:"totp_secret,omitempty"`
+}
+
+// APIKey the key of api, it is sent by the header "Authorization: Bearer <key>" or "X-API-Key: <key>".
+// the scopes: read, sign(send transaction and sign by the wallet of node, include read), admin(include all)
+type APIKey struct {
+	Name   string   `json:"name"`
+	Key    string   `json:"key"`
+	Scopes []string `json:"scopes"`
+}
+
+// DevnetID the net id of devnet
+const DevnetID = "govm_devnet"
+
+// PasswordEnv the environment variable of password, it overrides the password of conf.json
+const PasswordEnv = "GOVM_PASSWORD"
+
 var (
 	conf TConfig
+	mu   sync.RWMutex
 	// Version software version
 	Version string = "v0.5.7"
 	// BuildTime build time
@@ -56,18 +136,24 @@ func init() {
 		log.Println("must be 64 bit system")
 		os.Exit(2)
 	}
-	err := loadConfig()
+	c, err := loadConfig()
+	if err == nil {
+		err = Validate(c)
+	}
 	if err != nil {
 		log.Println("fail to read file,conf.json,", err)
+		fmt.Println("error configure,conf.json,", err)
 		os.Exit(2)
 	}
+	conf = c
 	log.Printf("software version:%s,build time:%s,git head:%s", Version, BuildTime, GitHead)
 }
 
-func loadConfig() error {
-	conf.CheckBlock = true
-	conf.AutoRollback = true
-	conf.VerifyNetData = true
+func loadConfig() (TConfig, error) {
+	var c TConfig
+	c.CheckBlock = true
+	c.AutoRollback = true
+	c.VerifyNetData = true
 	fn := "./conf/conf.json"
 	if _, err := os.Stat(fn); os.IsNotExist(err) {
 		data, _ := ioutil.ReadFile("./conf/conf.bak.json")
@@ -78,50 +164,66 @@ func loadConfig() error {
 	data, err := ioutil.ReadFile(fn)
 	if err != nil {
 		log.Println("fail to read file,conf.json")
-		return err
+		return c, err
 	}
-	err = json.Unmarshal(data, &conf)
+	err = json.Unmarshal(data, &c)
 	if err != nil {
 		log.Println("fail to Unmarshal configure,conf.json")
-		return err
+		return c, err
 	}
 	//log.Println("config info:", conf)
-	conf.CorePackName, _ = hex.DecodeString("ff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	c.CorePackName, _ = hex.DecodeString("ff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
 
-	if conf.WalletFile == "" {
-		conf.WalletFile = "./conf/wallet.key"
+	if c.WalletFile == "" {
+		c.WalletFile = "./conf/wallet.key"
+	}
+	if c.KeyStoreFile == "" {
+		c.KeyStoreFile = "./conf/keystore.json"
+	}
+	if pwd := os.Getenv(PasswordEnv); pwd != "" {
+		c.Password = pwd
 	}
-	if conf.Password == "" {
-		conf.Password = "govm_pwd@2019"
+	if c.Password == "" {
+		c.Password = wallet.DefaultPassword
 	}
-	if conf.HTTPAddress == "" {
-		conf.HTTPAddress = "127.0.0.1"
+	if c.DbDir == "" {
+		c.DbDir = "./db_dir"
 	}
-	if conf.TrustedServer == "" {
-		conf.TrustedServer = "http://govm.net:9090"
+	if c.HTTPAddress == "" {
+		c.HTTPAddress = "127.0.0.1"
+	}
+	if c.TrustedServer == "" {
+		c.TrustedServer = "http://govm.net:9090"
+	}
+	if c.AuditLog == "" {
+		c.AuditLog = "./log/audit.log"
+	}
+	if c.Devnet {
+		c.NetID = DevnetID
+		if c.GenesisFile == "" {
+			c.GenesisFile = "./conf/devnet.json"
+		}
+	}
+	// the trusted server only knows the blocks of main net
+	if c.GenesisFile != "" {
+		c.CheckBlock = false
 	}
 
-	return nil
+	return c, nil
 }
 
 // GetConf get configure
 func GetConf() TConfig {
+	mu.RLock()
+	defer mu.RUnlock()
 	return conf
 }
 
-// Reload reload config
-func Reload() error {
-	old := conf
-	err := loadConfig()
-	if err != nil {
-		log.Println("fail to reload config:", err)
-		conf = old
-	}
-	return err
-}
-
 // LoadWallet load wallet
 func LoadWallet(fileName, password string) {
+	if ok, _ := wallet.MigrateWallet(fileName, password); ok {
+		fmt.Println("upgrade the wallet file to version", wallet.WalletVersion, fileName)
+	}
 	w, err := wallet.LoadWallet(fileName, password)
 	if err != nil {
 		if _, exist := os.Stat(fileName); !os.IsNotExist(exist) {
@@ -140,7 +242,94 @@ func LoadWallet(fileName, password string) {
 		}
 		wallet.SaveWallet(fileName, password, w.Address, w.Key, w.SignPrefix)
 	}
+	mu.Lock()
+	defer mu.Unlock()
 	conf.PrivateKey = w.Key
 	conf.WalletAddr = w.Address
 	conf.SignPrefix = w.SignPrefix
 }
+
+// GetWallet get the wallet of the account(name or address of keystore),
+// return the wallet of node if from is empty
+func GetWallet(from string) (wallet.TWallet, error) {
+	c := GetConf()
+	if from == "" || from == hex.EncodeToString(c.WalletAddr) {
+		w := wallet.TWallet{}
+		w.Address = c.WalletAddr
+		w.AddressStr = hex.EncodeToString(c.WalletAddr)
+		w.Key = c.PrivateKey
+		w.SignPrefix = c.SignPrefix
+		return w, nil
+	}
+	return walletCache.get(c.KeyStoreFile, c.Password, from)
+}
+
+// keyStoreCache the opened keystore and the unlocked wallets, it avoids decrypting(scrypt) for every request.
+// it is reset if the keystore file is changed or the configure is reloaded
+type keyStoreCache struct {
+	mu       sync.Mutex
+	file     string
+	password string
+	modTime  time.Time
+	ks       *wallet.KeyStore
+	wallets  map[string]wallet.TWallet
+}
+
+var walletCache keyStoreCache
+
+func (kc *keyStoreCache) reset() {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.ks = nil
+	kc.wallets = nil
+}
+
+func (kc *keyStoreCache) get(file, password, name string) (wallet.TWallet, error) {
+	var modTime time.Time
+	if info, err := os.Stat(file); err == nil {
+		modTime = info.ModTime()
+	}
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.ks == nil || kc.file != file || kc.password != password || !kc.modTime.Equal(modTime) {
+		ks, err := wallet.OpenKeyStore(file, password)
+		if err != nil {
+			return wallet.TWallet{}, err
+		}
+		kc.file = file
+		kc.password = password
+		kc.modTime = modTime
+		kc.ks = ks
+		kc.wallets = make(map[string]wallet.TWallet)
+	}
+	w, ok := kc.wallets[name]
+	if !ok {
+		var err error
+		w, err = kc.ks.GetWallet(name)
+		if err != nil {
+			return wallet.TWallet{}, err
+		}
+		kc.wallets[name] = w
+	}
+	w.Key = append([]byte{}, w.Key...)
+	return w, nil
+}
+
+// CheckPassword warn if the password is the default password,
+// return error if DenyDefaultPassword is true
+func CheckPassword() error {
+	c := GetConf()
+	if c.Password != wallet.DefaultPassword {
+		return nil
+	}
+	msg := "the password of wallet is the default password, anyone who gets the wallet file can use it. " +
+		"please change it by govm-wallet and set \"password\" of conf.json or the environment variable " + PasswordEnv
+	log.Println("WARNING:", msg)
+	fmt.Println("**********************************************************")
+	fmt.Println("WARNING:", msg)
+	fmt.Println("**********************************************************")
+	if c.DenyDefaultPassword {
+		return fmt.Errorf("refuse to use the default password(deny_default_password)")
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package conf
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/govm-net/govm/approval"
+	"github.com/govm-net/govm/logger"
+	"github.com/govm-net/govm/wallet"
+)
+
+var confLog = logger.New(logger.SubConf)
+
+// restartFields the fields(json name) take effect after restart, they are not changed by Reload
+var restartFields = map[string]bool{
+	"server_host":     true,
+	"http_address":    true,
+	"http_port":       true,
+	"db_addr_type":    true,
+	"db_server_addr":  true,
+	"db_type":         true,
+	"db_dir":          true,
+	"password":        true,
+	"wallet_file":     true,
+	"keystore_file":   true,
+	"save_log":        true,
+	"save_node_info":  true,
+	"net_id":          true,
+	"verify_net_data": true,
+	"pprof_addr":      true,
+	"devnet":          true,
+	"genesis_file":    true,
+	"tls_cert_file":   true,
+	"tls_key_file":    true,
+	"audit_log":       true,
+}
+
+// APIScopes the scopes of api key
+var APIScopes = []string{"read", "sign", "admin"}
+
+// ApprovalMethods the methods of approval
+var ApprovalMethods = []string{"console", "policy", "webhook", "totp"}
+
+// the fields are loaded from the wallet, not from conf.json
+var walletFields = map[string]bool{
+	"core_pack_name": true,
+	"wallet_addr":    true,
+	"sign_prefix":    true,
+	"private_key":    true,
+}
+
+const minAPIKeyLen = 16
+
+var logLevels = []string{"debug", "info", "warn", "warning", "error", "off"}
+
+// ReloadResult the result of Reload
+type ReloadResult struct {
+	// Changed the fields(json name) changed by Reload
+	Changed []string `json:"changed"`
+	// NeedRestart the fields changed in conf.json, but take effect after restart
+	NeedRestart []string `json:"need_restart,omitempty"`
+}
+
+// ReloadHook it is called after the configure reloaded, changed is the json name of the changed fields(maybe empty)
+type ReloadHook func(old, c TConfig, changed []string)
+
+var (
+	hookMu   sync.Mutex
+	hooks    []ReloadHook
+	reloadMu sync.Mutex
+)
+
+// OnReload register the hook, it is called after the configure reloaded by Reload
+func OnReload(hook ReloadHook) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	hooks = append(hooks, hook)
+}
+
+func inList(list []string, s string) bool {
+	for _, it := range list {
+		if it == s {
+			return true
+		}
+	}
+	return false
+}
+
+func validLogLevel(level string) bool {
+	level = strings.ToLower(strings.TrimSpace(level))
+	for _, it := range logLevels {
+		if it == level {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate check the configure
+func Validate(c TConfig) error {
+	if c.HTTPPort < 0 || c.HTTPPort > 65535 {
+		return fmt.Errorf("error http_port:%d", c.HTTPPort)
+	}
+	if c.DbType != "" && c.DbType != "embedded" {
+		return fmt.Errorf("unknown db_type:%s, hope empty or embedded", c.DbType)
+	}
+	if c.TrustedServer != "" {
+		u, err := url.Parse(c.TrustedServer)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("error trusted_server:%s", c.TrustedServer)
+		}
+	}
+	if c.MinerConnLimit < 0 {
+		return fmt.Errorf("error miner_conn_limit:%d", c.MinerConnLimit)
+	}
+	if c.MempoolSize < 0 {
+		return fmt.Errorf("error mempool_size:%d", c.MempoolSize)
+	}
+	if c.LogLevel != "" && !validLogLevel(c.LogLevel) {
+		return fmt.Errorf("error log_level:%s", c.LogLevel)
+	}
+	for subsys, level := range c.LogLevels {
+		if subsys == "" || !validLogLevel(level) {
+			return fmt.Errorf("error log_levels:%s=%s", subsys, level)
+		}
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("tls_cert_file and tls_key_file must be set together")
+	}
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, it := range c.APIKeys {
+		if it.Name == "" || names[it.Name] {
+			return fmt.Errorf("empty or duplicate name of api_keys:%s", it.Name)
+		}
+		if len(it.Key) < minAPIKeyLen || keys[it.Key] || it.Key == c.AdminToken {
+			return fmt.Errorf("the key of api_keys is too short(<%d) or duplicate:%s", minAPIKeyLen, it.Name)
+		}
+		if len(it.Scopes) == 0 {
+			return fmt.Errorf("empty scopes of api_keys:%s", it.Name)
+		}
+		for _, s := range it.Scopes {
+			if !inList(APIScopes, s) {
+				return fmt.Errorf("unknown scope of api_keys:%s,%s", it.Name, s)
+			}
+		}
+		names[it.Name] = true
+		keys[it.Key] = true
+	}
+	if err := validateApproval(c.Approval, c.Spending); err != nil {
+		return err
+	}
+	return validateSpending(c.Spending)
+}
+
+func validatePayees(name string, payees []string) error {
+	for _, p := range payees {
+		if d, err := hex.DecodeString(p); err != nil || len(d) != wallet.AddressLength {
+			return fmt.Errorf("error payee of %s:%s", name, p)
+		}
+	}
+	return nil
+}
+
+func validateSpending(s SpendingConf) error {
+	for _, it := range s.Ops {
+		if !inList(approval.AllOps, it) {
+			return fmt.Errorf("unknown ops of spending:%s", it)
+		}
+	}
+	for _, it := range s.Chains {
+		if it == 0 {
+			return fmt.Errorf("error chain of spending:0")
+		}
+	}
+	return validatePayees("spending", s.Payees)
+}
+
+func validateApproval(a ApprovalConf, s SpendingConf) error {
+	for _, m := range a.Methods {
+		if !inList(ApprovalMethods, m) {
+			return fmt.Errorf("unknown method of approval:%s", m)
+		}
+		switch {
+		case m == "webhook" && a.WebhookURL == "":
+			return fmt.Errorf("empty webhook_url of approval")
+		case m == "totp" && a.TOTPSecret == "":
+			return fmt.Errorf("empty totp_secret of approval")
+		case m == "policy" && s.DailyLimit == 0:
+			return fmt.Errorf("policy of approval need daily_limit of spending")
+		}
+	}
+	if a.WebhookURL != "" {
+		u, err := url.Parse(a.WebhookURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("error webhook_url of approval:%s", a.WebhookURL)
+		}
+	}
+	if a.WebhookTimeout < 0 {
+		return fmt.Errorf("error webhook_timeout of approval:%d", a.WebhookTimeout)
+	}
+	return nil
+}
+
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// Reload reload conf.json, the configure is validated before applied,
+// the fields which need restart are not changed, the hooks are called after every successful reload
+func Reload() (ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	rst := ReloadResult{Changed: []string{}}
+	c, err := loadConfig()
+	if err == nil {
+		err = Validate(c)
+	}
+	if err != nil {
+		confLog.Error("fail to reload configure", logger.KeyError, err)
+		return rst, err
+	}
+
+	mu.Lock()
+	old := conf
+	ov := reflect.ValueOf(old)
+	cv := reflect.ValueOf(&c).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		name := jsonName(cv.Type().Field(i))
+		if reflect.DeepEqual(ov.Field(i).Interface(), cv.Field(i).Interface()) {
+			continue
+		}
+		if walletFields[name] {
+			cv.Field(i).Set(ov.Field(i))
+			continue
+		}
+		if restartFields[name] {
+			cv.Field(i).Set(ov.Field(i))
+			rst.NeedRestart = append(rst.NeedRestart, name)
+			continue
+		}
+		rst.Changed = append(rst.Changed, name)
+	}
+	conf = c
+	mu.Unlock()
+	walletCache.reset()
+
+	if len(rst.NeedRestart) > 0 {
+		confLog.Warn("reload configure, need restart", "fields", rst.NeedRestart)
+	}
+	if len(rst.Changed) > 0 {
+		confLog.Info("reload configure", "changed", rst.Changed)
+	}
+	hookMu.Lock()
+	list := append([]ReloadHook{}, hooks...)
+	hookMu.Unlock()
+	for _, hook := range list {
+		hook(old, c, rst.Changed)
+	}
+	return rst, nil
+}
@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/govm-net/govm/counter"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/runtime"
 )
 
@@ -105,7 +106,7 @@ func CreateAppFromSourceCode(fileName string, flag byte) ([]byte, uint64) {
 
 	out = append(out, code...)
 	appName := runtime.GetHash(out)
-	log.Printf("code info,fileName:%s,info:%v,appName:%x\n", fileName, info, appName)
+	coreLog.Info("code info", "file", fileName, "line_number", info.LineNum, "flag", info.Flag, logger.KeyApp, appName)
 	return out, l
 }
 
@@ -145,7 +146,7 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 		}
 		v.imports = append(v.imports, v.index-1)
 	case *ast.Package:
-		log.Println("package:", v.index, n)
+		coreLog.Debug("package", "index", v.index, "name", n.Name)
 	default:
 	}
 	return v
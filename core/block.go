@@ -2,15 +2,17 @@ package zff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
 
 import (
 	"fmt"
-	"log"
 	"runtime/debug"
 
 	"github.com/govm-net/govm/conf"
 	"github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/runtime"
 	"github.com/govm-net/govm/wallet"
 )
 
+var coreLog = logger.New(logger.SubCore)
+
 // const minHPLimit = 15
 // todo
 const minHPLimit = 2
@@ -254,7 +256,7 @@ func DecodeBlock(data []byte) *StBlock {
 
 	rst := wallet.Recover(out.Producer[:], out.sign, bData)
 	if !rst {
-		log.Println("fail to recover block")
+		coreLog.Warn("fail to recover block", logger.KeyChain, out.Chain, logger.KeyIndex, out.Index, "producer", out.Producer)
 		return nil
 	}
 	h := runtime.GetHash(data)
@@ -385,15 +387,18 @@ func ProcessBlockOfChain(chain uint64, key []byte) (err error) {
 	defer func() {
 		e := recover()
 		if e != nil {
-			log.Printf("fail to process block,chain:%d,key:%x,err:%s\n", chain, key, e)
-			log.Println(string(debug.Stack()))
+			coreLog.Error("fail to process block", logger.KeyChain, chain, logger.KeyBlock, key, logger.KeyError, e, "stack", string(debug.Stack()))
+			if runtime.IsRetryError(e) {
+				err = e.(error)
+				return
+			}
 			err = fmt.Errorf("recover:%s", e)
 		}
 	}()
 	client := database.GetClient()
 	err = client.OpenFlag(chain, key)
 	if err != nil {
-		log.Println("fail to open Flag,", err)
+		coreLog.Warn("fail to open Flag", logger.KeyChain, chain, logger.KeyBlock, key, logger.KeyError, err)
 		f := client.GetLastFlag(chain)
 		client.Cancel(chain, f)
 		client.Rollback(chain, f)
@@ -1,27 +1,43 @@
 package zff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
-	"log"
 	"runtime/debug"
 	"time"
 
 	"github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/runtime"
 )
 
 // MaxGuerdon MaxGuerdon
 const MaxGuerdon = maxGuerdon
 
+// checkBlockID the block used to check transactions,
+// not the first block, the transactions of the first block are special
+func checkBlockID(id uint64) uint64 {
+	if id > 2 {
+		return id - 1
+	}
+	return id
+}
+
 // CheckTransaction check trans for mine
 func CheckTransaction(chain uint64, tKey []byte) (err error) {
 	defer func() {
 		e := recover()
 		if e != nil {
-			log.Println("something error,", e)
-			log.Println(string(debug.Stack()))
-			err = fmt.Errorf("%s", e)
+			coreLog.Warn("fail to check transaction", logger.KeyChain, chain, logger.KeyTrans, tKey, logger.KeyError, e, "stack", string(debug.Stack()))
+			switch v := e.(type) {
+			case *runtime.TRetryError:
+				err = v
+			case *runtime.TAppError:
+				err = v
+			default:
+				err = fmt.Errorf("%s", e)
+			}
 		}
 	}()
 	if chain == 0 {
@@ -35,7 +51,7 @@ func CheckTransaction(chain uint64, tKey []byte) (err error) {
 	proc.initEnv(chain, []byte("testmode"))
 	runt := proc.iRuntime.(*runtime.TRuntime)
 	runt.SetTestMode()
-	key := proc.pLogBlockInfo.read(chain, proc.Encode(0, proc.ID-1))
+	key := proc.pLogBlockInfo.read(chain, proc.Encode(0, checkBlockID(proc.ID)))
 	stream := proc.pLogBlockInfo.read(chain, key[:])
 	if len(stream) == 0 {
 		return fmt.Errorf("fail to read block info:%x", key)
@@ -55,12 +71,105 @@ func CheckTransaction(chain uint64, tKey []byte) (err error) {
 	return nil
 }
 
+// SimulateResult the result of simulating transaction
+type SimulateResult struct {
+	Key Hash
+	// Error the panic message, it is empty if success
+	Error string
+	// Used the energy used by apps
+	Used   uint64
+	Events []runtime.TAppEvent
+	Keys   []runtime.TTouchedKey
+}
+
+// SimulateTransaction run the transaction(StTrans.Output()) in test mode like CheckTransaction,
+// the data is not persisted. the sign is not verified if the transaction is unsigned(the sign is empty)
+func SimulateTransaction(chain uint64, data []byte) (out SimulateResult) {
+	var runt *runtime.TRuntime
+	defer func() {
+		e := recover()
+		if runt != nil {
+			rst := runt.TestResult()
+			out.Used = rst.Used
+			out.Events = rst.Events
+			out.Keys = rst.Keys
+		}
+		if e != nil {
+			out.Error = fmt.Sprintf("%v", e)
+			if ae, ok := e.(*runtime.TAppError); ok {
+				out.Error = ae.Info
+			}
+		}
+	}()
+	if chain == 0 {
+		panic("not support,chain == 0")
+	}
+	if len(data) == 0 {
+		panic(ErrTransLength)
+	}
+	runtime.Decode(runtime.GetHash(data), &out.Key)
+	if data[0] > 0 {
+		_, err := DecodeTransaction(data)
+		if err != nil {
+			panic(err)
+		}
+	} else if len(data) < 1+binary.Size(TransactionHead{}) {
+		panic(ErrTransLength)
+	}
+	if runtime.DbExist(dbTransInfo{}, chain, out.Key[:]) {
+		panic("transaction is exist")
+	}
+
+	var proc processer
+	proc.initEnv(chain, []byte("testmode"))
+	runt = proc.iRuntime.(*runtime.TRuntime)
+	runt.SetTestMode()
+	proc.allowUnsigned = true
+	key := proc.pLogBlockInfo.read(chain, proc.Encode(0, checkBlockID(proc.ID)))
+	stream := proc.pLogBlockInfo.read(chain, key[:])
+	if len(stream) == 0 {
+		panic(fmt.Sprintf("fail to read block info:%x", key))
+	}
+	block := BlockInfo{}
+	proc.Decode(0, stream, &block)
+	runt.SetTestData(dbTransactionData{}, out.Key[:], data, proc.Time+TimeDay)
+	proc.processTransaction(block, out.Key)
+	return
+}
+
+// maxEstimateEnergy the max energy of the transaction used to estimate energy
+const maxEstimateEnergy = 1 << 40
+
+// EstimateEnergy run the app call with enough energy(limited by the balance of user) in test mode,
+// return the minimum energy of the transaction, the app can use half of the energy of transaction.
+// the sign is ignored
+func EstimateEnergy(chain uint64, trans StTrans) (uint64, SimulateResult) {
+	if trans.Ops != OpsRunApp {
+		return 0, SimulateResult{Error: "only support run app"}
+	}
+	trans.Chain = chain
+	trans.Sign = nil
+	trans.Energy = maxEstimateEnergy
+	coin := GetUserCoin(chain, trans.User[:])
+	if coin > trans.Cost && coin-trans.Cost < trans.Energy {
+		trans.Energy = coin - trans.Cost
+	}
+	data := trans.Output()
+	rst := SimulateTransaction(chain, data)
+	out := 2 * rst.Used
+	// the length of sign is less than 250
+	if min := uint64(len(data)) + 250; out < min {
+		out = min
+	}
+	return out, rst
+}
+
 // CheckTransList check trans list for mine
 func CheckTransList(chain uint64, factory func(uint64) Hash) (err error) {
 	defer func() {
 		e := recover()
 		if e != nil {
-			log.Println("CheckTransList error:", e)
+			coreLog.Warn("CheckTransList error", logger.KeyChain, chain, logger.KeyError, e)
 			err = fmt.Errorf("%s", e)
 		}
 		// log.Printf("CheckTransList input:%d,out:%d", len(keys), len(out))
@@ -69,7 +178,7 @@ func CheckTransList(chain uint64, factory func(uint64) Hash) (err error) {
 	proc.initEnv(chain, []byte("testmode"))
 	runt := proc.iRuntime.(*runtime.TRuntime)
 	runt.SetTestMode()
-	key := proc.pLogBlockInfo.read(chain, proc.Encode(0, proc.ID-1))
+	key := proc.pLogBlockInfo.read(chain, proc.Encode(0, checkBlockID(proc.ID)))
 	stream := proc.pLogBlockInfo.read(chain, key[:])
 	if len(stream) == 0 {
 		return nil
@@ -107,7 +216,7 @@ func NewTransProc(chain uint64, key []byte) *TransProc {
 	client := database.GetClient()
 	err := client.OpenFlag(chain, key)
 	if err != nil {
-		log.Println("fail to open Flag,", err)
+		coreLog.Warn("fail to open Flag", logger.KeyChain, chain, logger.KeyBlock, key, logger.KeyError, err)
 		f := client.GetLastFlag(chain)
 		client.Cancel(chain, f)
 		client.Rollback(chain, f)
@@ -123,8 +232,7 @@ func (p *TransProc) ProcTrans(key []byte) uint64 {
 		err := recover()
 		if err != nil {
 			result = 0
-			log.Println("[mine]fail to process trans:", err)
-			log.Println(string(debug.Stack()))
+			coreLog.Warn("fail to process trans for mining", logger.KeyChain, p.chain, logger.KeyTrans, key, logger.KeyError, err, "stack", string(debug.Stack()))
 		}
 	}()
 	var h Hash
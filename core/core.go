@@ -4,8 +4,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"io/ioutil"
-	"log"
 
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/runtime"
 )
 
@@ -112,6 +112,7 @@ type processer struct {
 	BaseInfo
 	iRuntime
 	isAdmin            bool
+	allowUnsigned      bool
 	sInfo              tSyncInfo
 	pDbBlockData       *DB
 	pDbTransactionData *DB
@@ -706,7 +707,11 @@ func (p *processer) processBlock(chain uint64, key Hash) {
 		assert(parent == block.Parent)
 	} else {
 		blockInterval := p.pDbStat.GetInt([]byte{StatBlockInterval})
-		assert(decT == blockInterval)
+		if IsDevnet() {
+			assert(decT >= blockInterval)
+		} else {
+			assert(decT == blockInterval)
+		}
 	}
 
 	p.adminReward(preB.Time)
@@ -729,14 +734,15 @@ func (p *processer) processBlock(chain uint64, key Hash) {
 		}
 		assertMsg(p.isAdmin, "not miner")
 
+		defHP := getDefaultHashPower()
 		if chain == 1 && p.ID < 10000 {
-			if hpLimit > defaultHashPower+2 {
+			if hpLimit > defHP+2 {
 				hp = hpLimit/hpStep - 2
 			} else {
-				hp = defaultHashPower / hpStep
+				hp = defHP / hpStep
 			}
 		} else {
-			hp = defaultHashPower / hpStep
+			hp = defHP / hpStep
 		}
 	}
 	hp = hp + hpLimit - hpLimit/hpStep
@@ -886,11 +892,19 @@ func (p *processer) processFirstBlock(block Block) {
 
 	if p.Chain == 1 {
 		p.pDbStat.SetValue([]byte{StatGuerdon}, uint64(maxGuerdon), maxDbLife)
-		p.pDbStat.SetValue([]byte{StatHashPower}, uint64(defaultHashPower), maxDbLife)
+		p.pDbStat.SetValue([]byte{StatHashPower}, getDefaultHashPower(), maxDbLife)
+		var adminList []Address
+		if genesis != nil {
+			adminList = genesis.Admins
+		} else {
+			for _, it := range firstAdmins {
+				var addr Address
+				addr.Decode(it)
+				adminList = append(adminList, addr)
+			}
+		}
 		var admins [AdminNum]Address
-		for i, it := range firstAdmins {
-			var addr Address
-			addr.Decode(it)
+		for i, addr := range adminList {
 			var admin = AdminInfo{1, 0}
 			p.pDbAdmin.SetValue(addr[:], admin, maxDbLife)
 			if i < AdminNum {
@@ -899,20 +913,28 @@ func (p *processer) processFirstBlock(block Block) {
 		}
 		p.pDbStat.SetValue([]byte{StatAdmin}, &admins, maxDbLife)
 
-		var redemption map[string]uint64
-		data, err := ioutil.ReadFile("./conf/redemption.json")
-		assert(err == nil)
-		err = json.Unmarshal(data, &redemption)
-		assert(err == nil)
 		var total uint64
-		for k, v := range redemption {
-			var addr Address
-			addr.Decode(k)
-			p.adminTransfer(Address{}, addr, v)
-			p.registerMiner(addr)
-			total += v
+		if genesis != nil {
+			for addr, v := range genesis.Accounts {
+				p.adminTransfer(Address{}, addr, v)
+				p.registerMiner(addr)
+				total += v
+			}
+		} else {
+			var redemption map[string]uint64
+			data, err := ioutil.ReadFile("./conf/redemption.json")
+			assert(err == nil)
+			err = json.Unmarshal(data, &redemption)
+			assert(err == nil)
+			for k, v := range redemption {
+				var addr Address
+				addr.Decode(k)
+				p.adminTransfer(Address{}, addr, v)
+				p.registerMiner(addr)
+				total += v
+			}
+			assertMsg(total == redemptionTotal, "error redemptionTotal")
 		}
-		assertMsg(total == redemptionTotal, "error redemptionTotal")
 		total += maxGuerdon
 		p.pDbStat.SetValue([]byte{StatTotalCoins}, total, maxDbLife)
 	} else {
@@ -921,7 +943,11 @@ func (p *processer) processFirstBlock(block Block) {
 		p.pDbStat.SetValue([]byte{StatTotalCoins}, old, maxDbLife)
 	}
 
-	p.pDbStat.SetValue([]byte{StatBlockSizeLimit}, uint64(blockSizeLimit), maxDbLife)
+	var sizeLimit uint64 = blockSizeLimit
+	if genesis != nil {
+		sizeLimit = genesis.BlockSizeLimit
+	}
+	p.pDbStat.SetValue([]byte{StatBlockSizeLimit}, sizeLimit, maxDbLife)
 	p.pDbStat.SetValue([]byte{StatBlockInterval}, getBlockInterval(p.Chain), maxDbLife)
 	p.pDbStat.Set([]byte{StatFirstBlockKey}, p.Key[:], maxDbLife)
 
@@ -972,8 +998,12 @@ func (p *processer) adminReward(preTime uint64) {
 	}
 }
 
+// getBlockInterval the interval of chain 1 is defined by genesis, the child chains are faster
 func getBlockInterval(chain uint64) uint64 {
 	var out uint64 = maxBlockInterval - minBlockInterval
+	if genesis != nil {
+		out = genesis.BlockInterval - minBlockInterval
+	}
 	for chain > 1 {
 		out = out * 15 / 16
 		chain = chain / 2
@@ -1134,7 +1164,7 @@ func (p *processer) processTransaction(block BlockInfo, key Hash) uint64 {
 	assert(len(ti) == 0)
 	data, _ := p.pDbTransactionData.Get(key[:])
 	signLen := data[0]
-	assert(signLen > 30)
+	assert(signLen > 30 || (p.allowUnsigned && signLen == 0))
 	k := p.getHash(data)
 	assert(k == key)
 
@@ -1147,7 +1177,7 @@ func (p *processer) processTransaction(block BlockInfo, key Hash) uint64 {
 	dataLen := len(trans.data)
 	trans.key = key
 
-	assert(p.Recover(trans.User[:], sign, signData))
+	assert(signLen == 0 || p.Recover(trans.User[:], sign, signData))
 
 	// transaction list
 	db := p.GetDB(statTransList{})
@@ -1308,8 +1338,8 @@ func (p *processer) pNewChain(producer Address, t Transaction) {
 	si.Producer = producer
 	si.Time = p.Time - blockSyncMax + 1
 	si.HashPower = p.pDbStat.GetInt([]byte{StatHashPower})
-	if si.HashPower < defaultHashPower {
-		si.HashPower = defaultHashPower
+	if defHP := getDefaultHashPower(); si.HashPower < defHP {
+		si.HashPower = defHP
 	}
 	p.pDbStat.GetValue([]byte{StatAdmin}, &si.AdminList)
 	var find bool
@@ -1637,8 +1667,7 @@ func (p *processer) pVote(trans Transaction) {
 	have := p.pDbCoin.GetInt(user[:])
 	if cost == 0 || have < cost {
 		if have < cost {
-			log.Printf("warning vote, chain:%d,have:%d,cost:%d,user:%x\n",
-				p.Chain, have, cost, user)
+			coreLog.Warn("not enough coin of vote", logger.KeyChain, p.Chain, "have", have, "cost", cost, "user", user)
 		}
 		return
 	}
@@ -0,0 +1,117 @@
+package zff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Genesis the specification of the first block, it replaces the built-in
+// producer(team), public address, admins and initial parameters of the main net
+type Genesis struct {
+	Producer   Address
+	PublicAddr Address
+	Admins     []Address
+	Accounts   map[Address]uint64
+	// BlockInterval the interval(ms) between blocks of chain 1, the child chains are faster
+	BlockInterval  uint64
+	BlockSizeLimit uint64
+	HashPower      uint64
+	// Devnet the interval between blocks is not less than BlockInterval(not equal),
+	// so the blocks can be produced on demand
+	Devnet bool
+}
+
+// genesis the genesis of private network, it is nil for the main net
+var genesis *Genesis
+
+var (
+	mainTeam       = team
+	mainPublicAddr = gPublicAddr
+)
+
+// Validate check the genesis and set the default value of the empty parameters
+func (g *Genesis) Validate() error {
+	if g.Producer.Empty() {
+		return errors.New("empty producer")
+	}
+	if g.Producer[0] == prefixOfPlublcAddr {
+		return fmt.Errorf("the producer is public address:%x", g.Producer)
+	}
+	if g.PublicAddr.Empty() {
+		g.PublicAddr = mainPublicAddr
+	}
+	if g.PublicAddr[0] != prefixOfPlublcAddr {
+		return fmt.Errorf("the prefix of public address must be %d", prefixOfPlublcAddr)
+	}
+	if len(g.Admins) == 0 || len(g.Admins) > AdminNum {
+		return fmt.Errorf("the number of admins must be 1-%d", AdminNum)
+	}
+	admins := make(map[Address]bool)
+	for _, it := range g.Admins {
+		if it.Empty() || it[0] == prefixOfPlublcAddr {
+			return fmt.Errorf("error admin:%x", it)
+		}
+		if admins[it] {
+			return fmt.Errorf("repeated admin:%x", it)
+		}
+		admins[it] = true
+	}
+	var total uint64 = maxGuerdon
+	for k, v := range g.Accounts {
+		if k.Empty() || k[0] == prefixOfPlublcAddr {
+			return fmt.Errorf("error account:%x", k)
+		}
+		if total+v < total {
+			return errors.New("the total coins overflow")
+		}
+		total += v
+	}
+	if g.BlockInterval == 0 {
+		g.BlockInterval = maxBlockInterval
+	}
+	if g.BlockInterval < minBlockInterval || g.BlockInterval > maxBlockInterval {
+		return fmt.Errorf("the block interval must be %d-%d", minBlockInterval, maxBlockInterval)
+	}
+	if g.BlockSizeLimit == 0 {
+		g.BlockSizeLimit = blockSizeLimit
+	}
+	if g.BlockSizeLimit < blockSizeLimit || g.BlockSizeLimit > 1<<32-1 {
+		return fmt.Errorf("the block size limit must be %d-%d", blockSizeLimit, uint64(1<<32-1))
+	}
+	if g.HashPower == 0 {
+		g.HashPower = defaultHashPower
+	}
+	if g.HashPower < hpStep {
+		return fmt.Errorf("the hash power must not be less than %d", hpStep)
+	}
+	return nil
+}
+
+// SetGenesis set the genesis of private network, reset to the main net if it is nil
+func SetGenesis(g *Genesis) error {
+	if g == nil {
+		genesis = nil
+		team = mainTeam
+		gPublicAddr = mainPublicAddr
+		return nil
+	}
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	genesis = g
+	team = g.Producer
+	gPublicAddr = g.PublicAddr
+	return nil
+}
+
+// IsDevnet return true if the genesis is devnet
+func IsDevnet() bool {
+	return genesis != nil && genesis.Devnet
+}
+
+func getDefaultHashPower() uint64 {
+	if genesis != nil {
+		return genesis.HashPower
+	}
+	return defaultHashPower
+}
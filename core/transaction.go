@@ -1,11 +1,12 @@
 package zff0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
-	"log"
 	"time"
 
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/runtime"
 	"github.com/govm-net/govm/wallet"
 )
@@ -138,12 +139,42 @@ func (t *StTrans) VoteAdmin(cost uint64, admin []byte) {
 	t.Data = admin
 }
 
+// Unvote Unvote, if voter is not empty, the admin cancel the vote of the voter
+func (t *StTrans) Unvote(voter []byte) {
+	t.Ops = OpsUnvote
+	t.Data = voter
+}
+
+// the reason of invalid transaction data
+var (
+	ErrTransLength = errors.New("error length of transaction")
+	ErrTransSign   = errors.New("error sign")
+	ErrTransUser   = errors.New("error user,public address")
+	ErrTransEnergy = errors.New("not enough energy")
+)
+
 // DecodeTrans decode transaction data
 func DecodeTrans(data []byte) *StTrans {
+	out, err := DecodeTransaction(data)
+	if err != nil {
+		coreLog.Debug("fail to decode transaction", logger.KeyError, err)
+		return nil
+	}
+	return out
+}
+
+// DecodeTransaction decode transaction data and recover the sign, return the reason if it is invalid
+func DecodeTransaction(data []byte) (*StTrans, error) {
 	out := new(StTrans)
-	signLen := data[0]
+	if len(data) == 0 {
+		return nil, ErrTransLength
+	}
+	signLen := int(data[0])
 	if signLen <= 30 || signLen >= 250 {
-		return nil
+		return nil, ErrTransSign
+	}
+	if len(data) < signLen+1+binary.Size(out.TransactionHead) {
+		return nil, ErrTransLength
 	}
 
 	out.Key = runtime.GetHash(data)
@@ -155,19 +186,17 @@ func DecodeTrans(data []byte) *StTrans {
 
 	rst := wallet.Recover(out.User[:], out.Sign, signData)
 	if !rst {
-		log.Println("error sign")
-		return nil
+		return nil, ErrTransSign
 	}
 
 	if out.User[0] == prefixOfPlublcAddr {
-		return nil
+		return nil, ErrTransUser
 	}
 
 	if out.Energy < uint64(len(data)) {
-		log.Println("not enough energy:", out.Energy)
-		return nil
+		return nil, ErrTransEnergy
 	}
-	return out
+	return out, nil
 }
 
 // IsExistTransaction Determine whether transaction exists
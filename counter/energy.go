@@ -39,3 +39,22 @@ func ConsumeEnergy(n uint64) {
 		panic("not enough energy")
 	}
 }
+
+// ResetEnergy reset the energy and the used energy,
+// the app worker run many calls in one process
+func ResetEnergy(n uint64) {
+	mu.Lock()
+	defer mu.Unlock()
+	if n == 0 {
+		panic(n)
+	}
+	energy = n
+	used = 0
+}
+
+// GetUsed get the used energy
+func GetUsed() uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return used
+}
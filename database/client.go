@@ -1,19 +1,42 @@
 package database
 
 import (
+	"os"
+
 	"github.com/govm-net/govm/conf"
 	"github.com/lengzhao/database/client"
 )
 
-var dfDB *client.Client
+// Client the interface of database client
+type Client interface {
+	OpenFlag(chain uint64, flag []byte) error
+	GetLastFlag(chain uint64) []byte
+	Commit(chain uint64, flag []byte) error
+	Cancel(chain uint64, flag []byte) error
+	Rollback(chain uint64, flag []byte) error
+	Set(chain uint64, tbName, key, value []byte) error
+	SetWithFlag(chain uint64, flag, tbName, key, value []byte) error
+	Get(chain uint64, tbName, key []byte) []byte
+	GetNextKey(chain uint64, tbName, preKey []byte) []byte
+	Exist(chain uint64, tbName, key []byte) bool
+	Close()
+}
+
+// DbTypeEmbedded the database run in the process of govm
+const DbTypeEmbedded = "embedded"
+
+// EnvAppProcess the environment variable of app process,
+// the app connects the embedded database of govm by rpc
+const EnvAppProcess = "GOVM_APP_PROCESS"
+
+var dfDB Client
 
 var clientNum int = 1
 
 // GetClient get database client
-func GetClient() *client.Client {
+func GetClient() Client {
 	if dfDB == nil {
-		c := conf.GetConf()
-		dfDB = client.New(c.DbAddrType, c.DbServerAddr, clientNum)
+		dfDB = newClient()
 	}
 	return dfDB
 }
@@ -22,7 +45,22 @@ func GetClient() *client.Client {
 func ChangeClientNumber(in int) {
 	if clientNum != in {
 		clientNum = in
-		c := conf.GetConf()
-		dfDB = client.New(c.DbAddrType, c.DbServerAddr, clientNum)
+		if conf.GetConf().DbType == DbTypeEmbedded && dfDB != nil {
+			return
+		}
+		dfDB = newClient()
+	}
+}
+
+// NewRPCClient new client of the database server
+func NewRPCClient(addrType, address string, num int) Client {
+	return client.New(addrType, address, num)
+}
+
+func newClient() Client {
+	c := conf.GetConf()
+	if c.DbType == DbTypeEmbedded && os.Getenv(EnvAppProcess) == "" {
+		return OpenEmbedded(c.DbDir, c.DbAddrType, c.DbServerAddr)
 	}
+	return client.New(c.DbAddrType, c.DbServerAddr, clientNum)
 }
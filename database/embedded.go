@@ -0,0 +1,158 @@
+package database
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/rpc"
+	"os"
+
+	"github.com/lengzhao/database/disk"
+	"github.com/lengzhao/database/server"
+)
+
+// Embedded the database run in the process of govm,
+// it also serve the rpc for the apps(they run in other process)
+type Embedded struct {
+	db *server.TDb
+	ln net.Listener
+}
+
+// OpenEmbedded open the embedded database,
+// if address is not empty, it will listen the address for apps
+func OpenEmbedded(dir, addrType, address string) *Embedded {
+	if dir == "" {
+		dir = "./db_dir"
+	}
+	os.MkdirAll(dir, 0755)
+	out := new(Embedded)
+	out.db = server.NewRPCObj(dir)
+	server.RegisterAPI(out.db, func(dir string, id uint64) server.DBApi {
+		m, err := disk.Open(dir)
+		if err != nil {
+			log.Println("fail to open embedded database:", dir, err)
+			return nil
+		}
+		return m
+	})
+	if address == "" {
+		return out
+	}
+	if addrType == "" {
+		addrType = "tcp"
+	}
+	ln, err := net.Listen(addrType, address)
+	if err != nil {
+		log.Println("fail to listen database address:", address, err)
+		return out
+	}
+	srv := rpc.NewServer()
+	srv.Register(out.db)
+	mux := http.NewServeMux()
+	mux.Handle(rpc.DefaultRPCPath, srv)
+	out.ln = ln
+	go http.Serve(ln, mux)
+	log.Println("embedded database listen:", addrType, address)
+	return out
+}
+
+// Close close the database
+func (e *Embedded) Close() {
+	if e.ln != nil {
+		e.ln.Close()
+		e.ln = nil
+	}
+	server.CloseRPCObj(e.db)
+}
+
+// OpenFlag open flag
+func (e *Embedded) OpenFlag(chain uint64, flag []byte) error {
+	var reply bool
+	return e.db.OpenFlag(&server.FlagArgs{Chain: chain, Flag: flag}, &reply)
+}
+
+// GetLastFlag get last flag
+func (e *Embedded) GetLastFlag(chain uint64) []byte {
+	var reply []byte
+	err := e.db.GetLastFlag(&chain, &reply)
+	if err != nil {
+		log.Println("fail to TDb.GetLastFlag:", chain, err)
+		return nil
+	}
+	return dup(reply)
+}
+
+// Commit commit flag
+func (e *Embedded) Commit(chain uint64, flag []byte) error {
+	var reply bool
+	return e.db.CommitFlag(&server.FlagArgs{Chain: chain, Flag: flag}, &reply)
+}
+
+// Cancel cancel flag
+func (e *Embedded) Cancel(chain uint64, flag []byte) error {
+	var reply bool
+	return e.db.CancelFlag(&server.FlagArgs{Chain: chain, Flag: flag}, &reply)
+}
+
+// Rollback rollback flag
+func (e *Embedded) Rollback(chain uint64, flag []byte) error {
+	var reply bool
+	return e.db.Rollback(&server.FlagArgs{Chain: chain, Flag: flag}, &reply)
+}
+
+// Set set data without flag
+func (e *Embedded) Set(chain uint64, tbName, key, value []byte) error {
+	var reply bool
+	args := server.SetArgs{Chain: chain, TbName: tbName, Key: dup(key), Value: dup(value)}
+	return e.db.Set(&args, &reply)
+}
+
+// SetWithFlag set data with flag
+func (e *Embedded) SetWithFlag(chain uint64, flag, tbName, key, value []byte) error {
+	var reply bool
+	args := server.SetWithFlagArgs{Chain: chain, Flag: flag, TbName: tbName, Key: dup(key), Value: dup(value)}
+	return e.db.SetWithFlag(&args, &reply)
+}
+
+// Get get data
+func (e *Embedded) Get(chain uint64, tbName, key []byte) []byte {
+	var reply []byte
+	err := e.db.Get(&server.GetArgs{Chain: chain, TbName: tbName, Key: key}, &reply)
+	if err != nil {
+		log.Println("fail to TDb.Get:", chain, err)
+		return nil
+	}
+	return dup(reply)
+}
+
+// GetNextKey get next key
+func (e *Embedded) GetNextKey(chain uint64, tbName, preKey []byte) []byte {
+	var reply []byte
+	err := e.db.GetNextKey(&server.GetArgs{Chain: chain, TbName: tbName, Key: preKey}, &reply)
+	if err != nil {
+		log.Println("fail to TDb.GetNextKey:", chain, err)
+		return nil
+	}
+	return dup(reply)
+}
+
+// Exist return true if the key exist
+func (e *Embedded) Exist(chain uint64, tbName, key []byte) bool {
+	var reply bool
+	err := e.db.Exist(&server.GetArgs{Chain: chain, TbName: tbName, Key: key}, &reply)
+	if err != nil {
+		log.Println("fail to TDb.Exist:", chain, err)
+		return false
+	}
+	return reply
+}
+
+// the data is not serialized as rpc, copy it to avoid sharing the cache
+func dup(in []byte) []byte {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]byte, len(in))
+	copy(out, in)
+	return out
+}
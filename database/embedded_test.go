@@ -0,0 +1,72 @@
+package database
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestEmbedded(t *testing.T) {
+	dir := path.Join(gDbRoot, "embedded")
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+	db := OpenEmbedded(dir, "tcp", "127.0.0.1:17779")
+	defer db.Close()
+	tbn := []byte("tbname")
+	key := []byte("key")
+	value := []byte("value")
+	flag := []byte("flag1")
+	if db.Exist(1, tbn, key) {
+		t.Fatal("hope not exist")
+	}
+	err := db.OpenFlag(1, flag)
+	if err != nil {
+		t.Fatal("fail to open flag.", err)
+	}
+	err = db.SetWithFlag(1, flag, tbn, key, value)
+	if err != nil {
+		t.Fatal("fail to set.", err)
+	}
+	err = db.Commit(1, flag)
+	if err != nil {
+		t.Fatal("fail to commit.", err)
+	}
+	if bytes.Compare(db.GetLastFlag(1), flag) != 0 {
+		t.Error("different flag")
+	}
+	nk := db.GetNextKey(1, tbn, nil)
+	if bytes.Compare(nk, key) != 0 {
+		t.Errorf("error next key:%x", nk)
+	}
+	// the reply is a copy, it does not change the data of database
+	nk[0] = 'x'
+	if bytes.Compare(db.GetNextKey(1, tbn, nil), key) != 0 {
+		t.Error("the next key is changed by the caller")
+	}
+
+	cli := NewRPCClient("tcp", "127.0.0.1:17779", 1)
+	defer cli.Close()
+	v := cli.Get(1, tbn, key)
+	if bytes.Compare(value, v) != 0 {
+		t.Errorf("hope:%x,get:%x\n", value, v)
+	}
+
+	err = db.Rollback(1, flag)
+	if err != nil {
+		t.Fatal("fail to rollback.", err)
+	}
+	if db.Get(1, tbn, key) != nil {
+		t.Error("hope nil after rollback")
+	}
+
+	// the value is a copy, it is not changed by the caller after set
+	val := []byte("value2")
+	if err = db.Set(1, tbn, key, val); err != nil {
+		t.Fatal("fail to set.", err)
+	}
+	val[0] = 'x'
+	if v = db.Get(1, tbn, key); string(v) != "value2" {
+		t.Errorf("the value is changed by the caller:%s", v)
+	}
+}
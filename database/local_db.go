@@ -222,3 +222,29 @@ func (d *LDB) LGetNext(chain uint64, tbName string, prefix []byte) (k, v []byte)
 	d.rdisk++
 	return
 }
+
+// LVisit visit the keys(>=start) of the disk table, stop when cb return false.
+// k and v are only valid in cb, do not write the ldb in cb
+func (d *LDB) LVisit(chain uint64, tbName string, start []byte, cb func(k, v []byte) bool) {
+	tn := fmt.Sprintf("%d:%s", chain, tbName)
+	d.ldb.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(tn))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		var k, v []byte
+		if len(start) > 0 {
+			k, v = c.Seek(start)
+		} else {
+			k, v = c.First()
+		}
+		for ; k != nil; k, v = c.Next() {
+			if !cb(k, v) {
+				break
+			}
+		}
+		return nil
+	})
+	d.rdisk++
+}
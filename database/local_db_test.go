@@ -250,3 +250,22 @@ func TestLDB7(t *testing.T) {
 		t.Error("hope read from disk")
 	}
 }
+
+func TestLVisit(t *testing.T) {
+	fn := path.Join(gDbRoot, "aaa.db")
+	os.Remove(fn)
+	db := NewLDB("aaa.db", 100)
+	defer db.Close()
+	tbn := "tbname"
+	for i := byte(1); i < 10; i++ {
+		db.LSet(1, tbn, []byte{i}, []byte{i})
+	}
+	var keys []byte
+	db.LVisit(1, tbn, []byte{3}, func(k, v []byte) bool {
+		keys = append(keys, k[0])
+		return len(keys) < 3
+	})
+	if bytes.Compare(keys, []byte{3, 4, 5}) != 0 {
+		t.Errorf("error keys:%x", keys)
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"errors"
 )
 
-// AesEncrypt 加密的定义结构
+// AesEncrypt 加密的定义结构, only used by the old wallet files, new data use Seal
 type AesEncrypt struct {
 	Key string
 }
@@ -0,0 +1,89 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// SealVersion the version of sealed data: scrypt + AES-256-GCM
+const SealVersion = 1
+
+const (
+	sealSaltLen   = 16
+	sealNonceLen  = 12
+	sealHeadLen   = 4 + sealSaltLen + sealNonceLen
+	maxScryptLogN = 20
+)
+
+// the params of scrypt for new sealed data, the params are saved in the data
+var (
+	ScryptLogN = 15
+	ScryptR    = 8
+	ScryptP    = 1
+)
+
+// ErrPassword the password is error or the data is modified
+var ErrPassword = errors.New("error password")
+
+func newGCM(password string, logN, r, p int, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(password), salt, 1<<uint(logN), r, p, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypt the data by password, the key is derived by scrypt with random salt,
+// out = version(1)+logN(1)+r(1)+p(1)+salt(16)+nonce(12)+AES-GCM(data)
+func Seal(password string, data []byte) ([]byte, error) {
+	head := make([]byte, sealHeadLen)
+	head[0] = SealVersion
+	head[1] = byte(ScryptLogN)
+	head[2] = byte(ScryptR)
+	head[3] = byte(ScryptP)
+	_, err := rand.Read(head[4:])
+	if err != nil {
+		return nil, err
+	}
+	salt := head[4 : 4+sealSaltLen]
+	nonce := head[4+sealSaltLen:]
+	aead, err := newGCM(password, ScryptLogN, ScryptR, ScryptP, salt)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(head, nonce, data, head), nil
+}
+
+// Open decrypt the data of Seal
+func Open(password string, data []byte) ([]byte, error) {
+	if len(data) < sealHeadLen {
+		return nil, errors.New("error length of sealed data")
+	}
+	if data[0] != SealVersion {
+		return nil, errors.New("unknown version of sealed data")
+	}
+	logN, r, p := int(data[1]), int(data[2]), int(data[3])
+	if logN == 0 || logN > maxScryptLogN || r == 0 || p == 0 {
+		return nil, errors.New("error params of sealed data")
+	}
+	head := data[:sealHeadLen]
+	salt := head[4 : 4+sealSaltLen]
+	nonce := head[4+sealSaltLen:]
+	aead, err := newGCM(password, logN, r, p, salt)
+	if err != nil {
+		return nil, err
+	}
+	out, err := aead.Open(nil, nonce, data[sealHeadLen:], head)
+	if err != nil {
+		return nil, ErrPassword
+	}
+	return out, nil
+}
@@ -0,0 +1,43 @@
+package encrypt
+
+import (
+	"fmt"
+	"log"
+	"testing"
+)
+
+func ExampleSeal() {
+	data, err := Seal("1234334", []byte("abcdef"))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	msg, err := Open("1234334", data)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(string(msg))
+
+	// Output: abcdef
+}
+
+func TestOpen(t *testing.T) {
+	data, err := Seal("1234334", []byte("abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Open("1234335", data)
+	if err != ErrPassword {
+		t.Error("hope error password:", err)
+	}
+	data[len(data)-1]++
+	_, err = Open("1234334", data)
+	if err != ErrPassword {
+		t.Error("hope error of modified data:", err)
+	}
+	_, err = Open("1234334", data[:10])
+	if err == nil {
+		t.Error("hope error of short data")
+	}
+}
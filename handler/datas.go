@@ -15,6 +15,7 @@ import (
 	core "github.com/govm-net/govm/core"
 	"github.com/govm-net/govm/database"
 	"github.com/govm-net/govm/event"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/messages"
 	"github.com/govm-net/govm/runtime"
 )
@@ -45,6 +46,13 @@ const (
 const downloadTimeout = 10
 const acceptTimeDeference = 120
 
+var (
+	syncLog = logger.New(logger.SubSync)
+	mineLog = logger.New(logger.SubMining)
+	p2pLog  = logger.New(logger.SubP2P)
+	nodeLog = logger.New(logger.SubNode)
+)
+
 var ldb *database.LDB
 var timeDifference int64
 var firstUpdateTime bool = true
@@ -54,7 +62,7 @@ var transForMinging map[uint64][]*transInfo
 func Init() {
 	ldb = database.NewLDB("local.db", 10000)
 	if ldb == nil {
-		log.Println("fail to open ldb,local.db")
+		nodeLog.Error("fail to open ldb", "file", "local.db")
 		os.Exit(2)
 	}
 	ldb.SetNotDisk(ldbBlockRunStat, 10000)
@@ -69,6 +77,15 @@ func Init() {
 	ldb.SetNotDisk(ldbProducer, 1000)
 	ldb.SetNotDisk(ldbBlockLocked, 10000)
 	transForMinging = make(map[uint64][]*transInfo)
+	initEventLog()
+	initExplorer()
+	c := conf.GetConf()
+	if c.GenesisFile != "" {
+		initGenesis()
+	}
+	if c.Devnet {
+		return
+	}
 	time.AfterFunc(time.Second*5, updateTimeDifference)
 	time.AfterFunc(time.Second*2, startCheckBlock)
 }
@@ -118,7 +135,7 @@ func SaveIDBlocks(chain, index uint64, ib IDBlocks) {
 	key := runtime.Encode(index)
 	data, err := json.Marshal(ib)
 	if err != nil {
-		log.Println("fail to Marshal IDBlocks.", err)
+		syncLog.Warn("fail to Marshal IDBlocks", logger.KeyChain, chain, logger.KeyIndex, index, logger.KeyError, err)
 		return
 	}
 	ldb.LSet(chain, ldbIDBlocks, key, data)
@@ -192,7 +209,7 @@ func pushTransInfo(chain uint64, info *transInfo) {
 	if lst == nil {
 		lst = make([]*transInfo, 0)
 	}
-	lst = append(lst, info)
+	lst = admitTransInfo(chain, lst, info)
 	transForMinging[chain] = lst
 	// log.Println("transInfo list1:", len(lst))
 }
@@ -202,7 +219,12 @@ func popTransInfo(chain uint64) *transInfo {
 	defer procMgr.mu.Unlock()
 	lst := transForMinging[chain]
 	// log.Println("transInfo list2:", len(lst))
+	for len(lst) > 0 && isExpired(lst[0]) {
+		mpStat.Add("expire", 1)
+		lst = lst[1:]
+	}
 	if len(lst) == 0 {
+		transForMinging[chain] = lst
 		return nil
 	}
 	out := lst[0]
@@ -210,6 +232,13 @@ func popTransInfo(chain uint64) *transInfo {
 	return out
 }
 
+// GetTransPoolSize get the number of transactions waiting for mining
+func GetTransPoolSize(chain uint64) int {
+	procMgr.mu.Lock()
+	defer procMgr.mu.Unlock()
+	return len(transForMinging[chain])
+}
+
 func getData(chain uint64, tb string, key []byte, value interface{}) {
 	v := ldb.LGet(chain, tb, key)
 	if len(v) == 0 {
@@ -370,14 +399,14 @@ func getReliability(b *core.StBlock) TReliability {
 func updateTimeDifference() {
 	server := conf.GetConf().TrustedServer
 	if server == "" {
-		log.Println("updateTimeDifference,server is null")
+		nodeLog.Warn("updateTimeDifference,server is null")
 		return
 	}
 	time.AfterFunc(time.Hour*25, updateTimeDifference)
 	start := time.Now().Unix()
 	resp, err := http.Get(server + "/api/v1/time")
 	if err != nil {
-		log.Println("fail to updateTimeDifference,", err)
+		nodeLog.Warn("fail to updateTimeDifference", "server", server, logger.KeyError, err)
 		return
 	}
 	end := time.Now().Unix()
@@ -385,23 +414,23 @@ func updateTimeDifference() {
 		end = start
 	}
 	if resp.StatusCode != http.StatusOK {
-		log.Println("error response of updateTimeDifference,", resp.Status)
+		nodeLog.Warn("error response of updateTimeDifference", "server", server, "status", resp.Status)
 		return
 	}
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("updateTimeDifference,fail to read body:", err)
+		nodeLog.Warn("updateTimeDifference,fail to read body", "server", server, logger.KeyError, err)
 		return
 	}
 	serverTime, err := strconv.ParseInt(string(data), 10, 64)
 	if err != nil {
-		log.Println("updateTimeDifference error:", string(data), err)
+		nodeLog.Warn("updateTimeDifference,error time", "server", server, "data", string(data), logger.KeyError, err)
 		return
 	}
 	selfTime := (start + end) / 2
 	if serverTime > selfTime+acceptTimeDeference || selfTime > serverTime+acceptTimeDeference {
-		log.Println("updateTimeDifference error, time difference over 120s.",
-			serverTime, selfTime, acceptTimeDeference)
+		nodeLog.Error("updateTimeDifference,time difference over 120s", "server_time", serverTime,
+			"self_time", selfTime, "accept", acceptTimeDeference)
 		if firstUpdateTime {
 			fmt.Println("error: system time error,update system time or change trusted_server in conf/conf")
 			os.Exit(2)
@@ -410,10 +439,21 @@ func updateTimeDifference() {
 	}
 	firstUpdateTime = false
 	timeDifference = serverTime - selfTime
-	log.Println("updateTimeDifference", timeDifference, start, end)
+	nodeLog.Info("updateTimeDifference", "difference", timeDifference, "start", start, "end", end)
+}
+
+// coreClock the clock of core time(ms), it is replaced by the test network
+var coreClock func() uint64
+
+// SetClock set the clock of core time(ms), reset to the system clock if it is nil
+func SetClock(clock func() uint64) {
+	coreClock = clock
 }
 
 func getCoreTimeNow() uint64 {
+	if coreClock != nil {
+		return coreClock()
+	}
 	now := time.Now().Unix() + timeDifference
 	return uint64(now) * 1000
 }
@@ -439,9 +479,8 @@ func needBroadcastBlock(chain uint64, rel TReliability) bool {
 		return false
 	}
 
-	log.Printf("new best,chain:%d,key:%x,index:%d,hp:%d,old hp:%d,old_id:%d,old_key:%x\n",
-		chain, rel.Key, rel.Index, rel.HashPower,
-		best.HashPower, best.Index, best.Key)
+	syncLog.Info("new best", logger.KeyChain, chain, logger.KeyBlock, rel.Key, logger.KeyIndex, rel.Index,
+		"hash_power", rel.HashPower, "old_hash_power", best.HashPower, "old_index", best.Index, "old_block", best.Key)
 	stream, _ = json.Marshal(rel)
 	ldb.LSet(chain, ldbStatus, dbKey, stream)
 
@@ -458,7 +497,7 @@ func GetBlockForMining(chain uint64) *core.StBlock {
 	}
 	err := json.Unmarshal(data, &out)
 	if err != nil {
-		log.Println("fail to unmarshal.", err)
+		mineLog.Warn("fail to unmarshal the block for mining", logger.KeyChain, chain, logger.KeyError, err)
 		return nil
 	}
 	if out.Time+tMinute < getCoreTimeNow() {
@@ -471,7 +510,7 @@ func setBlockForMining(chain uint64, block core.StBlock) {
 	var dbKey = []byte("mining")
 	data, err := json.Marshal(block)
 	if err != nil {
-		log.Println(err)
+		mineLog.Warn("fail to marshal the block for mining", logger.KeyChain, chain, logger.KeyError, err)
 	}
 	ldb.LSet(chain, ldbStatus, dbKey, data)
 	msg := new(messages.BlockForMining)
@@ -480,6 +519,23 @@ func setBlockForMining(chain uint64, block core.StBlock) {
 	event.Send(msg)
 }
 
+func sendNewHead(chain uint64, relia TReliability) {
+	msg := new(messages.NewHead)
+	msg.Chain = chain
+	msg.Index = relia.Index
+	msg.Key = relia.Key[:]
+	msg.Time = relia.Time
+	msg.Producer = relia.Producer[:]
+	if !relia.TransListHash.Empty() {
+		data := core.ReadTransList(chain, relia.TransListHash[:])
+		for _, k := range core.ParseTransList(data) {
+			key := k
+			msg.TransList = append(msg.TransList, key[:])
+		}
+	}
+	event.Send(msg)
+}
+
 func setBlockProducer(chain, index uint64, producer core.Address) {
 	ldb.LSet(chain, ldbProducer, runtime.Encode(index), producer[:])
 }
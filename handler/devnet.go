@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/govm-net/govm/conf"
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/logger"
+	"github.com/govm-net/govm/runtime"
+)
+
+// DefDevnetFunds the coins of the producer if the genesis has no pre-funded account
+const DefDevnetFunds = 10000 * core.MaxGuerdon
+
+// devTime the time of the last block produced by devnet
+var devTime uint64
+var devMu sync.Mutex
+
+// devnetNow the core time of devnet, the blocks produced on demand may be later than the system time
+func devnetNow() uint64 {
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	if t := atomic.LoadUint64(&devTime); t > now {
+		return t
+	}
+	return now
+}
+
+// initDevnet set the clock of devnet
+func initDevnet() {
+	atomic.StoreUint64(&devTime, core.GetBlockTime(1))
+	SetClock(devnetNow)
+}
+
+// DevnetMine produce a block of devnet with the transactions waiting for mining,
+// it returns after the block is processed
+func DevnetMine(chain uint64) (TReliability, error) {
+	var out TReliability
+	if !core.IsDevnet() {
+		return out, errors.New("not devnet")
+	}
+	devMu.Lock()
+	defer devMu.Unlock()
+	processEvent(chain)
+	index := core.GetLastBlockIndex(chain)
+	if index == 0 {
+		return out, errors.New("not exist the chain")
+	}
+
+	block := newBlockForMining(chain)
+	runtime.Decode(conf.GetConf().WalletAddr, &block.Producer)
+	if now := devnetNow(); block.Time < now {
+		block.Time = now
+	}
+	if block.Time > atomic.LoadUint64(&devTime) {
+		atomic.StoreUint64(&devTime, block.Time)
+	}
+	if !produceBlock(chain, block) {
+		return out, errors.New("fail to sign the block")
+	}
+	// processEvent returns at once if the chain is being processed by others
+	for i := 0; core.GetLastBlockIndex(chain) < block.Index; i++ {
+		if i >= 100 {
+			return out, fmt.Errorf("fail to process the block,index:%d,key:%x", block.Index, block.Key)
+		}
+		if i > 0 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		processEvent(chain)
+	}
+	out = ReadBlockReliability(chain, block.Key[:])
+	mineLog.Info("devnet mine", logger.KeyChain, chain, logger.KeyIndex, block.Index, logger.KeyBlock, block.Key)
+	return out, nil
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/event"
+	"github.com/govm-net/govm/messages"
+)
+
+const (
+	ldbEventLog   = "event_log"   //blockID+seq:EventLog
+	ldbEventApp   = "event_app"   //app+blockID+seq:1
+	ldbEventTrans = "event_trans" //transKey+blockID+seq:1
+)
+
+const (
+	eventKeyLen       = 12
+	maxEventQueryNum  = 100
+	maxEventScanNum   = 10000
+	defaultEventLimit = 20
+)
+
+// EventLog the event of app(runtime.Event), indexed by chain/block/transaction/app
+type EventLog struct {
+	Chain      uint64   `json:"chain,omitempty"`
+	BlockIndex uint64   `json:"block_index,omitempty"`
+	BlockKey   string   `json:"block_key,omitempty"`
+	TransKey   string   `json:"trans_key,omitempty"`
+	App        string   `json:"app,omitempty"`
+	Struct     string   `json:"struct,omitempty"`
+	Event      string   `json:"event,omitempty"`
+	Params     []string `json:"params,omitempty"`
+	Cursor     string   `json:"cursor,omitempty"`
+}
+
+// EventQuery the filter of event log, zero value means any
+type EventQuery struct {
+	FromBlock uint64
+	ToBlock   uint64
+	TransKey  []byte
+	App       []byte
+	Struct    string
+	Event     string
+	// Cursor the cursor of last event, return the events after it
+	Cursor []byte
+	Limit  int
+}
+
+// pendingEvents the events of the processing block,
+// they are saved after the block processed
+type pendingEvents struct {
+	block    []byte
+	trans    []byte
+	events   []EventLog
+	msgs     []*messages.AppEvent
+	receipt  *Receipt
+	receipts []*Receipt
+}
+
+var elog *database.LDB
+var pendingMu sync.Mutex
+var pending = make(map[uint64]*pendingEvents)
+
+func initEventLog() {
+	elog = database.NewLDB("event_log.db", 100)
+	if elog == nil {
+		nodeLog.Error("fail to open ldb", "file", "event_log.db")
+		return
+	}
+	event.RegisterConsumer(func(m event.Message) error {
+		switch msg := m.(type) {
+		case *messages.AppEvent:
+			addEventLog(msg)
+		case *messages.AppRun:
+			addEnergyUsed(msg)
+		case *messages.NewHead:
+			saveEventLog(msg)
+		}
+		return nil
+	})
+}
+
+func addEventLog(msg *messages.AppEvent) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	p := pending[msg.Chain]
+	if p == nil || bytes.Compare(p.block, msg.Block) != 0 {
+		p = &pendingEvents{block: msg.Block}
+		pending[msg.Chain] = p
+	}
+	if msg.Struct == "dbTransInfo" && len(msg.Params) > 0 {
+		switch msg.Event {
+		case "start_transaction":
+			p.trans = msg.Params[0]
+			p.receipt = &Receipt{Chain: msg.Chain, TransKey: hex.EncodeToString(p.trans)}
+		case "finish_transaction":
+			if p.receipt != nil {
+				p.receipt.Status = ReceiptSuccess
+				p.receipts = append(p.receipts, p.receipt)
+			}
+			p.trans = nil
+			p.receipt = nil
+			return
+		}
+	}
+	e := EventLog{Chain: msg.Chain, Struct: msg.Struct, Event: msg.Event}
+	e.BlockKey = hex.EncodeToString(msg.Block)
+	e.TransKey = hex.EncodeToString(p.trans)
+	e.App = hex.EncodeToString(msg.App)
+	for _, it := range msg.Params {
+		e.Params = append(e.Params, hex.EncodeToString(it))
+	}
+	p.events = append(p.events, e)
+	p.msgs = append(p.msgs, msg)
+	if p.receipt != nil {
+		p.receipt.Events = append(p.receipt.Events, e)
+	}
+}
+
+func eventKey(index uint64, seq uint32) []byte {
+	out := make([]byte, eventKeyLen)
+	binary.BigEndian.PutUint64(out, index)
+	binary.BigEndian.PutUint32(out[8:], seq)
+	return out
+}
+
+func saveEventLog(msg *messages.NewHead) {
+	pendingMu.Lock()
+	p := pending[msg.Chain]
+	if p != nil && bytes.Compare(p.block, msg.Key) == 0 {
+		delete(pending, msg.Chain)
+	} else {
+		p = nil
+	}
+	pendingMu.Unlock()
+
+	deleteEventLog(msg.Chain, msg.Index)
+	if p == nil {
+		return
+	}
+	for _, r := range p.receipts {
+		saveReceipt(msg.Chain, msg.Index, r)
+	}
+	for i, e := range p.events {
+		e.BlockIndex = msg.Index
+		k := eventKey(msg.Index, uint32(i))
+		d, _ := json.Marshal(e)
+		elog.LSet(msg.Chain, ldbEventLog, k, d)
+		app, _ := hex.DecodeString(e.App)
+		elog.LSet(msg.Chain, ldbEventApp, append(app, k...), []byte{1})
+		if e.TransKey != "" {
+			tk, _ := hex.DecodeString(e.TransKey)
+			elog.LSet(msg.Chain, ldbEventTrans, append(tk, k...), []byte{1})
+		}
+	}
+	if len(p.msgs) > 0 {
+		event.Send(&messages.BlockEvents{Chain: msg.Chain, Index: msg.Index, Key: msg.Key, Events: p.msgs})
+	}
+}
+
+// deleteEventLog delete the events of the block index(the block is replaced)
+func deleteEventLog(chain, index uint64) {
+	var list []EventLog
+	var keys [][]byte
+	elog.LVisit(chain, ldbEventLog, eventKey(index, 0), func(k, v []byte) bool {
+		if binary.BigEndian.Uint64(k) != index {
+			return false
+		}
+		var e EventLog
+		json.Unmarshal(v, &e)
+		list = append(list, e)
+		keys = append(keys, append([]byte{}, k...))
+		return true
+	})
+	for i, k := range keys {
+		if list[i].Struct == "dbTransInfo" && list[i].Event == "start_transaction" {
+			tk, _ := hex.DecodeString(list[i].TransKey)
+			elog.LSet(chain, ldbReceipt, tk, nil)
+		}
+		elog.LSet(chain, ldbEventLog, k, nil)
+		app, _ := hex.DecodeString(list[i].App)
+		elog.LSet(chain, ldbEventApp, append(app, k...), nil)
+		if list[i].TransKey != "" {
+			tk, _ := hex.DecodeString(list[i].TransKey)
+			elog.LSet(chain, ldbEventTrans, append(tk, k...), nil)
+		}
+	}
+}
+
+// QueryEventLog query the event log, return the events and the cursor of next page(nil if end)
+func QueryEventLog(chain uint64, q EventQuery) ([]EventLog, []byte) {
+	if elog == nil {
+		return nil, nil
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultEventLimit
+	}
+	if q.Limit > maxEventQueryNum {
+		q.Limit = maxEventQueryNum
+	}
+	if q.ToBlock == 0 {
+		q.ToBlock = ^uint64(0)
+	}
+	tbName := ldbEventLog
+	var prefix []byte
+	switch {
+	case len(q.TransKey) > 0:
+		tbName = ldbEventTrans
+		prefix = q.TransKey
+	case len(q.App) > 0:
+		tbName = ldbEventApp
+		prefix = q.App
+	}
+	start := append([]byte{}, prefix...)
+	if len(q.Cursor) == eventKeyLen {
+		start = append(start, q.Cursor...)
+	} else {
+		start = append(start, eventKey(q.FromBlock, 0)...)
+	}
+
+	scanLimit := q.Limit + 1
+	if q.Struct != "" || q.Event != "" {
+		scanLimit = maxEventScanNum
+	}
+	var keys [][]byte
+	var more bool
+	elog.LVisit(chain, tbName, start, func(k, v []byte) bool {
+		if len(k) != len(prefix)+eventKeyLen || !bytes.HasPrefix(k, prefix) {
+			return false
+		}
+		k = k[len(prefix):]
+		if bytes.Compare(k, q.Cursor) == 0 {
+			return true
+		}
+		index := binary.BigEndian.Uint64(k)
+		if index > q.ToBlock {
+			return false
+		}
+		if index < q.FromBlock {
+			return true
+		}
+		if len(keys) >= scanLimit {
+			more = true
+			return false
+		}
+		keys = append(keys, append([]byte{}, k...))
+		return true
+	})
+
+	var out []EventLog
+	var next []byte
+	for i, k := range keys {
+		if len(out) >= q.Limit {
+			next = keys[i-1]
+			break
+		}
+		var e EventLog
+		v := elog.LGet(chain, ldbEventLog, k)
+		if json.Unmarshal(v, &e) != nil {
+			continue
+		}
+		e.Cursor = hex.EncodeToString(k)
+		if q.Struct != "" && q.Struct != e.Struct {
+			continue
+		}
+		if q.Event != "" && q.Event != e.Event {
+			continue
+		}
+		out = append(out, e)
+	}
+	if next == nil && more && len(keys) > 0 {
+		next = keys[len(keys)-1]
+	}
+	return out, next
+}
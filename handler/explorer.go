@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/event"
+	"github.com/govm-net/govm/logger"
+	"github.com/govm-net/govm/messages"
+)
+
+const (
+	ldbAddrTrans  = "addr_trans"  //address+^blockID+seq:AddrTrans
+	ldbBlockAddrs = "block_addrs" //blockID:address+^blockID+seq list
+	ldbIndexed    = "explorer"    //"height":the last indexed blockID
+)
+
+const (
+	addrTransKeyLen  = core.AddressLen + eventKeyLen
+	maxExplorerLimit = 100
+	defExplorerLimit = 20
+)
+
+// direction of the transaction for the address
+const (
+	DirOut = "out"
+	DirIn  = "in"
+)
+
+// AddrTrans the transaction of address, indexed by explorer
+type AddrTrans struct {
+	Chain      uint64 `json:"chain,omitempty"`
+	BlockIndex uint64 `json:"block_index,omitempty"`
+	BlockKey   string `json:"block_key,omitempty"`
+	TransKey   string `json:"trans_key,omitempty"`
+	Ops        uint8  `json:"ops"`
+	Direction  string `json:"direction,omitempty"`
+	Peer       string `json:"peer,omitempty"`
+	Cost       uint64 `json:"cost,omitempty"`
+	Time       uint64 `json:"time,omitempty"`
+	Cursor     string `json:"cursor,omitempty"`
+}
+
+// AddrTransQuery the filter of address history, zero value means any
+type AddrTransQuery struct {
+	FromBlock uint64
+	ToBlock   uint64
+	// Cursor the cursor of last item, return the items after it
+	Cursor []byte
+	Limit  int
+}
+
+var explorer *database.LDB
+var explorerMu sync.Mutex
+var heightKey = []byte("height")
+
+func initExplorer() {
+	explorer = database.NewLDB("explorer.db", 100)
+	if explorer == nil {
+		nodeLog.Error("fail to open ldb", "file", "explorer.db")
+		return
+	}
+	event.RegisterConsumer(func(m event.Message) error {
+		if msg, ok := m.(*messages.NewHead); ok {
+			explorerMu.Lock()
+			indexBlock(msg)
+			explorerMu.Unlock()
+		}
+		return nil
+	})
+	go backfillExplorer()
+}
+
+// getIndexedHeight get the last indexed block index of the chain
+func getIndexedHeight(chain uint64) uint64 {
+	var out uint64
+	if d := explorer.LGet(chain, ldbIndexed, heightKey); len(d) == 8 {
+		out = binary.BigEndian.Uint64(d)
+	}
+	return out
+}
+
+// setIndexedHeight update the indexed height, the index is continuous from the first block.
+// the higher block(the index is not continuous) is indexed by NewHead, but it is indexed again by backfill
+func setIndexedHeight(chain, index uint64) {
+	if index > getIndexedHeight(chain)+1 {
+		return
+	}
+	d := make([]byte, 8)
+	binary.BigEndian.PutUint64(d, index)
+	explorer.LSet(chain, ldbIndexed, heightKey, d)
+}
+
+// blockHead the message of the block on the chain, nil if the block not exist
+func blockHead(chain, index uint64) *messages.NewHead {
+	key := core.GetTheBlockKey(chain, index)
+	data := core.ReadBlockData(chain, key)
+	if len(data) == 0 {
+		return nil
+	}
+	block := core.DecodeBlock(data)
+	if block == nil {
+		return nil
+	}
+	msg := &messages.NewHead{Chain: chain, Index: block.Index, Key: block.Key[:], Time: block.Time,
+		Producer: block.Producer[:]}
+	if !block.TransListHash.Empty() {
+		for _, k := range core.ParseTransList(core.ReadTransList(chain, block.TransListHash[:])) {
+			key := k
+			msg.TransList = append(msg.TransList, key[:])
+		}
+	}
+	return msg
+}
+
+// backfillExplorer index the blocks from the last indexed block to the head of every chain,
+// they are the blocks processed before the explorer is enabled or when the node is stopped
+func backfillExplorer() {
+	for _, chain := range GetChains() {
+		from := getIndexedHeight(chain) + 1
+		var count int
+		for i := from; i <= core.GetLastBlockIndex(chain); i++ {
+			msg := blockHead(chain, i)
+			if msg == nil {
+				nodeLog.Warn("fail to backfill explorer, block not found", logger.KeyChain, chain, logger.KeyIndex, i)
+				break
+			}
+			explorerMu.Lock()
+			indexBlock(msg)
+			explorerMu.Unlock()
+			count++
+		}
+		if count > 0 {
+			nodeLog.Info("backfill explorer", logger.KeyChain, chain, "from", from, "count", count)
+		}
+	}
+}
+
+// addrTransKey the newest is the first one
+func addrTransKey(addr core.Address, index uint64, seq uint32) []byte {
+	return append(addr[:], eventKey(^index, seq)...)
+}
+
+// getPeerOfTrans get the receiver of the transaction, empty if it not exist
+func getPeerOfTrans(t *core.StTrans) (peer core.Address) {
+	switch t.Ops {
+	case core.OpsTransfer, core.OpsVote:
+		if len(t.Data) >= core.AddressLen {
+			copy(peer[:], t.Data)
+		}
+	case core.OpsRegisterMiner:
+		if len(t.Data) >= 8+core.AddressLen {
+			copy(peer[:], t.Data[8:])
+		}
+	}
+	return
+}
+
+// indexBlock record the transactions of the block by address
+func indexBlock(msg *messages.NewHead) {
+	deleteBlockIndex(msg.Chain, msg.Index)
+	var list []byte
+	var seq uint32
+	add := func(addr core.Address, it AddrTrans) {
+		k := addrTransKey(addr, msg.Index, seq)
+		seq++
+		d, _ := json.Marshal(it)
+		explorer.LSet(msg.Chain, ldbAddrTrans, k, d)
+		list = append(list, k...)
+	}
+	for _, key := range msg.TransList {
+		data := core.ReadTransactionData(msg.Chain, key)
+		trans, err := core.DecodeTransaction(data)
+		if err != nil {
+			continue
+		}
+		it := AddrTrans{Chain: msg.Chain, BlockIndex: msg.Index, Ops: trans.Ops}
+		it.BlockKey = hex.EncodeToString(msg.Key)
+		it.TransKey = hex.EncodeToString(key)
+		it.Cost = trans.Cost
+		it.Time = trans.Time
+		peer := getPeerOfTrans(trans)
+		it.Direction = DirOut
+		if !peer.Empty() {
+			it.Peer = hex.EncodeToString(peer[:])
+		}
+		add(trans.User, it)
+		if peer.Empty() || peer == trans.User {
+			continue
+		}
+		it.Direction = DirIn
+		it.Peer = hex.EncodeToString(trans.User[:])
+		add(peer, it)
+	}
+	if len(list) > 0 {
+		explorer.LSet(msg.Chain, ldbBlockAddrs, eventKey(msg.Index, 0)[:8], list)
+	}
+	setIndexedHeight(msg.Chain, msg.Index)
+}
+
+// deleteBlockIndex delete the index of the block index(the block is replaced)
+func deleteBlockIndex(chain, index uint64) {
+	bk := eventKey(index, 0)[:8]
+	list := explorer.LGet(chain, ldbBlockAddrs, bk)
+	for i := 0; i+addrTransKeyLen <= len(list); i += addrTransKeyLen {
+		explorer.LSet(chain, ldbAddrTrans, list[i:i+addrTransKeyLen], nil)
+	}
+	if len(list) > 0 {
+		explorer.LSet(chain, ldbBlockAddrs, bk, nil)
+	}
+}
+
+// QueryAddrTrans query the transactions of the address(newest first),
+// return the items and the cursor of next page(nil if end)
+func QueryAddrTrans(chain uint64, addr core.Address, q AddrTransQuery) ([]AddrTrans, []byte) {
+	if explorer == nil {
+		return nil, nil
+	}
+	if q.Limit <= 0 {
+		q.Limit = defExplorerLimit
+	}
+	if q.Limit > maxExplorerLimit {
+		q.Limit = maxExplorerLimit
+	}
+	last := core.GetLastBlockIndex(chain)
+	if q.ToBlock == 0 || q.ToBlock > last {
+		q.ToBlock = last
+	}
+	start := addrTransKey(addr, q.ToBlock, 0)
+	if len(q.Cursor) == eventKeyLen {
+		start = append(addr[:], q.Cursor...)
+	}
+	var out []AddrTrans
+	var next []byte
+	explorer.LVisit(chain, ldbAddrTrans, start, func(k, v []byte) bool {
+		if len(k) != addrTransKeyLen || !bytes.HasPrefix(k, addr[:]) {
+			return false
+		}
+		c := k[core.AddressLen:]
+		if bytes.Compare(c, q.Cursor) == 0 {
+			return true
+		}
+		index := ^binary.BigEndian.Uint64(c)
+		if index < q.FromBlock {
+			return false
+		}
+		if index > q.ToBlock {
+			// the index of rollback blocks
+			return true
+		}
+		if len(out) >= q.Limit {
+			next, _ = hex.DecodeString(out[len(out)-1].Cursor)
+			return false
+		}
+		var it AddrTrans
+		if json.Unmarshal(v, &it) != nil {
+			return true
+		}
+		it.Cursor = hex.EncodeToString(c)
+		out = append(out, it)
+		return true
+	})
+	return out, next
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"bytes"
+	"container/heap"
+	"sort"
+
+	core "github.com/govm-net/govm/core"
+)
+
+// the params of fee estimation
+const (
+	DefFeeBlocks = 10
+	MaxFeeBlocks = 100
+)
+
+// FeeEstimate the fee(energy) per byte of the transactions in recent blocks
+type FeeEstimate struct {
+	Chain     uint64 `json:"chain"`
+	Blocks    int    `json:"blocks"`
+	TransNum  int    `json:"trans_num"`
+	PoolSize  int    `json:"pool_size"`
+	FromBlock uint64 `json:"from_block,omitempty"`
+	ToBlock   uint64 `json:"to_block,omitempty"`
+	// the fee per byte
+	Min    float64 `json:"min"`
+	Low    float64 `json:"low"`
+	Median float64 `json:"median"`
+	High   float64 `json:"high"`
+	Max    float64 `json:"max"`
+}
+
+// senderQueue the transactions of one sender, ordered by time
+type senderQueue []*transInfo
+
+// transHeap the first transaction of every sender, the highest priority is the first
+type transHeap []senderQueue
+
+func (h transHeap) Len() int            { return len(h) }
+func (h transHeap) Less(i, j int) bool  { return isPrior(h[i][0], h[j][0]) }
+func (h transHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *transHeap) Push(x interface{}) { *h = append(*h, x.(senderQueue)) }
+func (h *transHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// isPrior return true if a should be mined before b: higher fee per byte, older, smaller key
+func isPrior(a, b *transInfo) bool {
+	fa, fb := feePerByte(a), feePerByte(b)
+	if fa != fb {
+		return fa > fb
+	}
+	if a.Time != b.Time {
+		return a.Time < b.Time
+	}
+	return bytes.Compare(a.Key[:], b.Key[:]) < 0
+}
+
+// sortTrans order the transactions by fee per byte,
+// the transactions of the same sender are ordered by time
+func sortTrans(lst []*transInfo) []*transInfo {
+	senders := make(map[core.Address]senderQueue)
+	for _, it := range lst {
+		senders[it.User] = append(senders[it.User], it)
+	}
+	h := make(transHeap, 0, len(senders))
+	for _, q := range senders {
+		sort.Slice(q, func(i, j int) bool {
+			if q[i].Time != q[j].Time {
+				return q[i].Time < q[j].Time
+			}
+			return bytes.Compare(q[i].Key[:], q[j].Key[:]) < 0
+		})
+		h = append(h, q)
+	}
+	heap.Init(&h)
+	out := make([]*transInfo, 0, len(lst))
+	for h.Len() > 0 {
+		q := h[0]
+		out = append(out, q[0])
+		if len(q) > 1 {
+			h[0] = q[1:]
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return out
+}
+
+// sortTransPool order the transactions waiting for mining by priority
+func sortTransPool(chain uint64) {
+	procMgr.mu.Lock()
+	defer procMgr.mu.Unlock()
+	lst := transForMinging[chain]
+	if len(lst) < 2 {
+		return
+	}
+	transForMinging[chain] = sortTrans(lst)
+}
+
+// EstimateFee estimate the fee per byte by the transactions of recent blocks
+func EstimateFee(chain uint64, blocks int) FeeEstimate {
+	if blocks <= 0 {
+		blocks = DefFeeBlocks
+	}
+	if blocks > MaxFeeBlocks {
+		blocks = MaxFeeBlocks
+	}
+	out := FeeEstimate{Chain: chain}
+	out.PoolSize = GetTransPoolSize(chain)
+	var fees []float64
+	last := core.GetLastBlockIndex(chain)
+	for i := last; i > 0 && out.Blocks < blocks; i-- {
+		data := core.ReadBlockData(chain, core.GetTheBlockKey(chain, i))
+		if len(data) == 0 {
+			break
+		}
+		block := core.DecodeBlock(data)
+		if block == nil {
+			break
+		}
+		out.Blocks++
+		if out.ToBlock == 0 {
+			out.ToBlock = i
+		}
+		out.FromBlock = i
+		if block.TransListHash.Empty() {
+			continue
+		}
+		list := core.ParseTransList(core.ReadTransList(chain, block.TransListHash[:]))
+		for _, k := range list {
+			td := core.ReadTransactionData(chain, k[:])
+			trans, err := core.DecodeTransaction(td)
+			if err != nil {
+				continue
+			}
+			fees = append(fees, float64(trans.Energy)/float64(len(td)))
+		}
+	}
+	out.TransNum = len(fees)
+	if len(fees) == 0 {
+		// the transaction must have Energy > size
+		out.Min, out.Low, out.Median, out.High, out.Max = 1, 1, 1, 1, 1
+		return out
+	}
+	sort.Float64s(fees)
+	percent := func(p int) float64 {
+		return fees[(len(fees)-1)*p/100]
+	}
+	out.Min = fees[0]
+	out.Low = percent(25)
+	out.Median = percent(50)
+	out.High = percent(90)
+	out.Max = fees[len(fees)-1]
+	return out
+}
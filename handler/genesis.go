@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
-	"time"
 
 	"github.com/govm-net/govm/conf"
 	core "github.com/govm-net/govm/core"
@@ -11,8 +13,179 @@ import (
 	"github.com/govm-net/govm/wallet"
 )
 
-func createFirstBlock() {
-	t := time.Date(2020, 5, 22, 0, 0, 0, 0, time.UTC)
+// GenesisSpec the genesis file of private network, the addresses are hex strings.
+// the producer is required except devnet(the wallet of node), the producer is always an admin.
+// the empty parameters are the same as the main net
+type GenesisSpec struct {
+	NetID          string            `json:"net_id,omitempty"`
+	Time           uint64            `json:"time,omitempty"`
+	Producer       string            `json:"producer,omitempty"`
+	PublicAddress  string            `json:"public_address,omitempty"`
+	Admins         []string          `json:"admins,omitempty"`
+	Accounts       map[string]uint64 `json:"accounts,omitempty"`
+	BlockInterval  uint64            `json:"block_interval,omitempty"`
+	BlockSizeLimit uint64            `json:"block_size_limit,omitempty"`
+	HashPower      uint64            `json:"hash_power,omitempty"`
+	Devnet         bool              `json:"devnet,omitempty"`
+}
+
+func decodeAddress(in string) (core.Address, error) {
+	var out core.Address
+	d, err := hex.DecodeString(in)
+	if err != nil {
+		return out, err
+	}
+	if len(d) != core.AddressLen {
+		return out, fmt.Errorf("error address length:%s", in)
+	}
+	runtime.Decode(d, &out)
+	return out, nil
+}
+
+// LoadGenesisSpec load the genesis file, return error if the file not exist
+func LoadGenesisSpec(fn string) (GenesisSpec, error) {
+	var out GenesisSpec
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return out, err
+	}
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+// createDevnetSpec create the genesis file of devnet if it not exist,
+// it is the same as conf/devnet.json, the producer is the wallet of node
+func createDevnetSpec(fn, netID string) error {
+	if _, err := os.Stat(fn); !os.IsNotExist(err) {
+		return err
+	}
+	spec := GenesisSpec{NetID: netID, BlockInterval: 1000, BlockSizeLimit: 1 << 20,
+		HashPower: 20000, Devnet: true}
+	data, _ := json.MarshalIndent(spec, "", "    ")
+	fmt.Println("create the genesis file of devnet:", fn)
+	return ioutil.WriteFile(fn, data, 0644)
+}
+
+// Genesis convert to the genesis of core and validate it, producer is the default producer
+func (s GenesisSpec) Genesis(producer []byte) (*core.Genesis, error) {
+	var err error
+	out := new(core.Genesis)
+	out.BlockInterval = s.BlockInterval
+	out.BlockSizeLimit = s.BlockSizeLimit
+	out.HashPower = s.HashPower
+	out.Devnet = s.Devnet
+	if s.Producer == "" {
+		if !s.Devnet {
+			return nil, fmt.Errorf("the producer of genesis is required")
+		}
+		runtime.Decode(producer, &out.Producer)
+	} else {
+		out.Producer, err = decodeAddress(s.Producer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.PublicAddress != "" {
+		out.PublicAddr, err = decodeAddress(s.PublicAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+	out.Admins = append(out.Admins, out.Producer)
+	for _, it := range s.Admins {
+		addr, err := decodeAddress(it)
+		if err != nil {
+			return nil, err
+		}
+		if addr != out.Producer {
+			out.Admins = append(out.Admins, addr)
+		}
+	}
+	out.Accounts = make(map[core.Address]uint64)
+	for k, v := range s.Accounts {
+		addr, err := decodeAddress(k)
+		if err != nil {
+			return nil, err
+		}
+		out.Accounts[addr] += v
+	}
+	if s.Devnet && len(out.Accounts) == 0 {
+		out.Accounts[out.Producer] = DefDevnetFunds
+	}
+	return out, out.Validate()
+}
+
+// Hash the hash of the genesis parameters
+func (s GenesisSpec) Hash() []byte {
+	data, _ := json.Marshal(s)
+	return wallet.GetHash(data)
+}
+
+// GenesisNetID get the net id of node, the hash of genesis is bound to the net id of private network,
+// the nodes and database with different genesis file are rejected
+func GenesisNetID() (string, error) {
+	c := conf.GetConf()
+	if c.GenesisFile == "" || c.Devnet {
+		return c.NetID, nil
+	}
+	spec, err := LoadGenesisSpec(c.GenesisFile)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s_%x", c.NetID, spec.Hash()[:4]), nil
+}
+
+// initGenesis load the genesis file of private network, create the first block if the node is the producer
+func initGenesis() {
+	c := conf.GetConf()
+	if c.Devnet {
+		if err := createDevnetSpec(c.GenesisFile, c.NetID); err != nil {
+			fmt.Println("fail to create the genesis file:", c.GenesisFile, err)
+			os.Exit(2)
+		}
+	}
+	spec, err := LoadGenesisSpec(c.GenesisFile)
+	if err != nil {
+		fmt.Println("fail to load the genesis file:", c.GenesisFile, err)
+		os.Exit(2)
+	}
+	if spec.NetID != "" && spec.NetID != c.NetID {
+		fmt.Printf("different net id of genesis,hope:%s,get:%s\n", c.NetID, spec.NetID)
+		os.Exit(2)
+	}
+	if c.Devnet {
+		spec.Devnet = true
+	}
+	g, err := spec.Genesis(c.WalletAddr)
+	if err == nil {
+		err = core.SetGenesis(g)
+	}
+	if err != nil {
+		fmt.Println("error genesis:", c.GenesisFile, err)
+		os.Exit(2)
+	}
+	if c.Devnet {
+		initDevnet()
+	}
+	if core.GetLastBlockIndex(1) > 0 {
+		return
+	}
+	var addr core.Address
+	runtime.Decode(c.WalletAddr, &addr)
+	if addr != g.Producer {
+		if c.Devnet {
+			fmt.Printf("the producer of devnet must be the wallet of node,producer:%x,wallet:%x\n",
+				g.Producer, addr)
+			os.Exit(2)
+		}
+		fmt.Printf("wait the first block from the producer:%x\n", g.Producer)
+		return
+	}
+	createFirstBlock(spec.Time)
+}
+
+// createFirstBlock create the first block at the time(ms), use the core time if it is 0
+func createFirstBlock(t uint64) {
 	id := core.GetLastBlockIndex(1)
 	if id > 0 {
 		fmt.Println("exist first block")
@@ -25,8 +198,10 @@ func createFirstBlock() {
 
 	block := new(core.StBlock)
 	block.Index = 1
-	// block.Time = uint64(time.Now().Unix()-10) * 1000
-	block.Time = uint64(t.Unix() * 1000)
+	block.Time = t
+	if block.Time == 0 {
+		block.Time = getCoreTimeNow()
+	}
 	runtime.Decode(c.WalletAddr, &block.Producer)
 	for {
 		signData := block.GetSignData()
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenesisSpec(t *testing.T) {
+	producer := make([]byte, 24)
+	producer[0] = 1
+	spec := GenesisSpec{NetID: "private", BlockInterval: 1000}
+	if _, err := spec.Genesis(producer); err == nil {
+		t.Error("hope the producer is required by private network")
+	}
+	spec.Devnet = true
+	if _, err := spec.Genesis(producer); err != nil {
+		t.Error("the producer of devnet is the wallet of node:", err)
+	}
+
+	other := spec
+	other.HashPower = 100
+	if bytes.Equal(spec.Hash(), other.Hash()) {
+		t.Error("hope different hash of different genesis")
+	}
+}
@@ -4,14 +4,16 @@ import (
 	"errors"
 	"expvar"
 	"fmt"
-	"log"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/govm-net/govm/conf"
 	core "github.com/govm-net/govm/core"
 	"github.com/govm-net/govm/event"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/messages"
+	"github.com/govm-net/govm/runtime"
 	"github.com/lengzhao/libp2p"
 	"github.com/lengzhao/libp2p/plugins"
 )
@@ -122,19 +124,24 @@ func (p *InternalPlugin) event(m event.Message) error {
 	switch msg := m.(type) {
 	case *messages.NewTransaction:
 		if core.IsExistTransaction(msg.Chain, msg.Key) {
-			log.Printf("[event]trans is exist,chain:%d,key:%x\n", msg.Chain, msg.Key)
+			p2pLog.Debug("trans is exist", logger.KeyChain, msg.Chain, logger.KeyTrans, msg.Key)
 			return nil
 		}
-		log.Printf("create new trans:%x,\n", msg.Key[:])
+		p2pLog.Info("create new trans", logger.KeyChain, msg.Chain, logger.KeyTrans, msg.Key)
+		undropTrans(msg.Chain, msg.Key)
 		err := core.WriteTransaction(msg.Chain, msg.Data)
 		if err != nil {
-			log.Println("fail to write transaction:", err)
+			p2pLog.Warn("fail to write transaction", logger.KeyChain, msg.Chain, logger.KeyTrans, msg.Key, logger.KeyError, err)
 			return err
 		}
 		err = core.CheckTransaction(msg.Chain, msg.Key)
 		if err != nil {
-			log.Printf("fail to new transaction.chain:%d,key:%x,error:%s\n", msg.Chain, msg.Key, err)
-			core.DeleteTransaction(msg.Chain, msg.Key)
+			p2pLog.Warn("fail to new transaction", logger.KeyChain, msg.Chain, logger.KeyTrans, msg.Key, logger.KeyError, err)
+			saveFailedReceipt(msg.Chain, msg.Key, err)
+			recordReject(msg.Chain, msg.Key, err)
+			if !runtime.IsRetryError(err) {
+				core.DeleteTransaction(msg.Chain, msg.Key)
+			}
 			return err
 		}
 
@@ -156,7 +163,11 @@ func (p *InternalPlugin) event(m event.Message) error {
 			p.network.SendInternalMsg(&messages.BaseMsg{Type: messages.BroadcastMsg, Msg: m})
 			err := processTransaction(msg.Chain, msg.Key, msg.Data)
 			if err != nil {
-				log.Printf("result of trans:%x,err :%s\n", msg.Key, err)
+				p2pLog.Warn("result of trans", logger.KeyChain, msg.Chain, logger.KeyTrans, msg.Key, logger.KeyError, err)
+				return
+			}
+			if core.IsDevnet() && conf.GetConf().DevnetAutoMine {
+				DevnetMine(msg.Chain)
 			}
 		}()
 		return nil
@@ -165,7 +176,11 @@ func (p *InternalPlugin) event(m event.Message) error {
 		if id == 0 {
 			return errors.New("not exist the chain")
 		}
-		log.Println("do mine:", msg.Chain)
+		mineLog.Info("do mine", logger.KeyChain, msg.Chain)
+		if core.IsDevnet() {
+			go DevnetMine(msg.Chain)
+			return nil
+		}
 		m := &messages.ReqBlockInfo{Chain: msg.Chain, Index: id}
 		p.network.SendInternalMsg(&messages.BaseMsg{Type: messages.RandsendMsg, Msg: m})
 		go doMining(msg.Chain)
@@ -189,6 +204,7 @@ func (p *InternalPlugin) event(m event.Message) error {
 
 			err = core.CheckTransaction(msg.Chain, msg.Key)
 			if err != nil {
+				recordReject(msg.Chain, msg.Key, err)
 				return err
 			}
 
@@ -210,23 +226,22 @@ func (p *InternalPlugin) event(m event.Message) error {
 		rel := ReadBlockReliability(msg.Chain, msg.Key)
 		if rel.Index != 0 {
 			// exist
-			log.Printf("exist the block:%x\n", msg.Key)
+			p2pLog.Debug("exist the block", logger.KeyChain, msg.Chain, logger.KeyBlock, msg.Key)
 			return nil
 		}
 		err := processBlock(msg.Chain, msg.Key, msg.Data)
 		if err != nil {
-			log.Printf("error block,chain:%d,key:%x,err:%s\n",
-				msg.Chain, msg.Key, err)
+			p2pLog.Warn("error block", logger.KeyChain, msg.Chain, logger.KeyBlock, msg.Key, logger.KeyError, err)
 			return err
 		}
 		rel = ReadBlockReliability(msg.Chain, msg.Key)
 		if rel.Index == 0 || rel.Key.Empty() {
-			log.Printf("err rel,index:%d block:%x\n", rel.Index, rel.Key)
+			p2pLog.Warn("error reliability", logger.KeyChain, msg.Chain, logger.KeyIndex, rel.Index, logger.KeyBlock, rel.Key)
 			return nil
 		}
 
 		if !core.IsMiner(msg.Chain, rel.Producer[:]) {
-			log.Printf("not miner,index:%d block:%x\n", rel.Index, rel.Key)
+			p2pLog.Warn("not miner", logger.KeyChain, msg.Chain, logger.KeyIndex, rel.Index, logger.KeyBlock, rel.Key)
 			return fmt.Errorf("not miner")
 		}
 
@@ -243,7 +258,7 @@ func (p *InternalPlugin) event(m event.Message) error {
 
 		preRel := ReadBlockReliability(msg.Chain, rel.Previous[:])
 		if rel.Producer == preRel.Producer {
-			log.Printf("same previous,index:%d block:%x\n", rel.Index, rel.Key)
+			p2pLog.Debug("same previous", logger.KeyChain, msg.Chain, logger.KeyIndex, rel.Index, logger.KeyBlock, rel.Key)
 			return nil
 		}
 
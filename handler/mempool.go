@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"time"
+
+	"github.com/govm-net/govm/conf"
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/logger"
+	"github.com/govm-net/govm/runtime"
+)
+
+// the default limits of mempool(transForMinging)
+const (
+	DefMempoolSize   = 10000
+	DefMempoolMaxAge = 24 * 60 // minutes
+)
+
+// PoolTrans the transaction waiting for mining
+type PoolTrans struct {
+	Key        string  `json:"key"`
+	User       string  `json:"user"`
+	Ops        uint8   `json:"ops"`
+	Size       uint32  `json:"size"`
+	Energy     uint64  `json:"energy"`
+	FeePerByte float64 `json:"fee_per_byte"`
+	Cost       uint64  `json:"cost,omitempty"`
+	Time       uint64  `json:"time"`
+	// Age seconds since the time of transaction
+	Age uint64 `json:"age"`
+}
+
+// RejectInfo the reason why the transaction was rejected by core.CheckTransaction
+type RejectInfo struct {
+	Chain    uint64 `json:"chain"`
+	TransKey string `json:"trans_key"`
+	Reason   string `json:"reason"`
+	// Retry the error is temporary, the transaction may be accepted later
+	Retry bool  `json:"retry,omitempty"`
+	Time  int64 `json:"time"`
+}
+
+// DropInfo the transaction dropped from mempool by the operator(DropTransaction)
+type DropInfo struct {
+	Chain    uint64 `json:"chain"`
+	TransKey string `json:"trans_key"`
+	User     string `json:"user"`
+	// Operator the caller(name of api key) who dropped the transaction
+	Operator string `json:"operator"`
+	Time     int64  `json:"time"`
+	// Resubmit the transaction is submitted again by user, it can be admitted again
+	Resubmit bool `json:"resubmit,omitempty"`
+}
+
+type keyOfTrans struct {
+	Chain uint64
+	Key   core.Hash
+}
+
+var (
+	rejectedTrans = database.NewLRUCache(10000)
+	droppedTrans  = database.NewLRUCache(10000)
+	mpStat        = expvar.NewMap("mempool")
+)
+
+func getMempoolLimit() (size int, maxAge uint64) {
+	c := conf.GetConf()
+	size = c.MempoolSize
+	if size <= 0 {
+		size = DefMempoolSize
+	}
+	maxAge = c.MempoolMaxAge
+	if maxAge == 0 {
+		maxAge = DefMempoolMaxAge
+	}
+	return size, maxAge * tMinute
+}
+
+func transKeyOf(chain uint64, key []byte) keyOfTrans {
+	out := keyOfTrans{Chain: chain}
+	copy(out.Key[:], key)
+	return out
+}
+
+func feePerByte(t *transInfo) float64 {
+	if t.Size == 0 {
+		return float64(t.Energy)
+	}
+	return float64(t.Energy) / float64(t.Size)
+}
+
+// isWorse return true if a should be evicted before b: expired, lower fee per byte, older
+func isWorse(a, b *transInfo, expire uint64) bool {
+	ea := a.Time < expire
+	eb := b.Time < expire
+	if ea != eb {
+		return ea
+	}
+	fa, fb := feePerByte(a), feePerByte(b)
+	if fa != fb {
+		return fa < fb
+	}
+	return a.Time < b.Time
+}
+
+// admitTransInfo check the limits of mempool, return the list after admit/evict,
+// must be called with procMgr.mu
+func admitTransInfo(chain uint64, lst []*transInfo, info *transInfo) []*transInfo {
+	if v, ok := droppedTrans.Get(keyOfTrans{chain, info.Key}); ok && !v.(DropInfo).Resubmit {
+		mpStat.Add("dropped", 1)
+		return lst
+	}
+	size, maxAge := getMempoolLimit()
+	var expire uint64
+	if now := getCoreTimeNow(); now > maxAge {
+		expire = now - maxAge
+	}
+	if info.Time < expire {
+		mpStat.Add("expire", 1)
+		return lst
+	}
+	if len(lst) < size {
+		return append(lst, info)
+	}
+	worst := -1
+	for i, it := range lst {
+		if worst < 0 || isWorse(it, lst[worst], expire) {
+			worst = i
+		}
+	}
+	if worst < 0 || !isWorse(lst[worst], info, expire) {
+		mpStat.Add("full", 1)
+		return lst
+	}
+	mpStat.Add("evict", 1)
+	out := make([]*transInfo, 0, len(lst))
+	out = append(out, lst[:worst]...)
+	out = append(out, lst[worst+1:]...)
+	return append(out, info)
+}
+
+// isExpired return true if the transaction is too old for mempool
+func isExpired(info *transInfo) bool {
+	_, maxAge := getMempoolLimit()
+	now := getCoreTimeNow()
+	return now > maxAge && info.Time < now-maxAge
+}
+
+// ListTransPool list the transactions waiting for mining, return the total number and the items
+func ListTransPool(chain uint64, offset, limit int) (int, []PoolTrans) {
+	procMgr.mu.Lock()
+	lst := make([]*transInfo, len(transForMinging[chain]))
+	copy(lst, transForMinging[chain])
+	procMgr.mu.Unlock()
+	now := getCoreTimeNow()
+	out := []PoolTrans{}
+	for i := offset; i < len(lst) && len(out) < limit; i++ {
+		t := lst[i]
+		it := PoolTrans{Key: hex.EncodeToString(t.Key[:]), User: hex.EncodeToString(t.User[:])}
+		it.Ops = t.Ops
+		it.Size = t.Size
+		it.Energy = t.Energy
+		it.FeePerByte = feePerByte(t)
+		it.Cost = t.Cost
+		it.Time = t.Time
+		if now > t.Time {
+			it.Age = (now - t.Time) / 1000
+		}
+		out = append(out, it)
+	}
+	return len(lst), out
+}
+
+// DropTransaction remove the transaction from mempool, it will not be mined by self
+// and not admitted again from network. operator is the caller who drops it.
+// the data of transaction is deleted if it is not in any block
+func DropTransaction(chain uint64, key []byte, operator string) error {
+	info, err := dropTransInfo(chain, key, operator)
+	if err != nil {
+		return err
+	}
+	nodeLog.Warn("drop transaction from mempool", logger.KeyChain, chain, logger.KeyTrans, key,
+		"user", info.User, "operator", operator)
+	if core.GetTransInfo(chain, key).BlockID == 0 {
+		core.DeleteTransaction(chain, key)
+		ldb.LSet(chain, ldbBroadcastTrans, key, nil)
+	}
+	return nil
+}
+
+// dropTransInfo remove the transaction from mempool and record the drop
+func dropTransInfo(chain uint64, key []byte, operator string) (DropInfo, error) {
+	k := transKeyOf(chain, key)
+	procMgr.mu.Lock()
+	lst := transForMinging[chain]
+	found := -1
+	for i, it := range lst {
+		if it.Key == k.Key {
+			found = i
+			break
+		}
+	}
+	var info DropInfo
+	if found >= 0 {
+		info = DropInfo{Chain: chain, TransKey: hex.EncodeToString(key), Operator: operator}
+		info.User = hex.EncodeToString(lst[found].User[:])
+		info.Time = time.Now().Unix()
+		out := make([]*transInfo, 0, len(lst))
+		out = append(out, lst[:found]...)
+		out = append(out, lst[found+1:]...)
+		transForMinging[chain] = out
+	}
+	procMgr.mu.Unlock()
+	if found < 0 {
+		return info, errors.New("not found the transaction in mempool")
+	}
+	droppedTrans.Set(k, info)
+	mpStat.Add("drop", 1)
+	return info, nil
+}
+
+// undropTrans the transaction is submitted again by user
+func undropTrans(chain uint64, key []byte) {
+	k := transKeyOf(chain, key)
+	if v, ok := droppedTrans.Get(k); ok {
+		info := v.(DropInfo)
+		info.Resubmit = true
+		droppedTrans.Set(k, info)
+	}
+}
+
+// GetDropInfo get the record of the transaction dropped by the operator, nil if not found
+func GetDropInfo(chain uint64, key []byte) *DropInfo {
+	v, ok := droppedTrans.Get(transKeyOf(chain, key))
+	if !ok {
+		return nil
+	}
+	info := v.(DropInfo)
+	return &info
+}
+
+// recordReject record the reason of core.CheckTransaction
+func recordReject(chain uint64, key []byte, err error) {
+	if err == nil {
+		return
+	}
+	info := RejectInfo{Chain: chain, TransKey: hex.EncodeToString(key)}
+	info.Reason = err.Error()
+	info.Retry = runtime.IsRetryError(err)
+	info.Time = time.Now().Unix()
+	rejectedTrans.Set(transKeyOf(chain, key), info)
+	mpStat.Add("reject", 1)
+}
+
+// GetRejectInfo get the reason why the transaction was rejected, nil if not found
+func GetRejectInfo(chain uint64, key []byte) *RejectInfo {
+	v, ok := rejectedTrans.Get(transKeyOf(chain, key))
+	if !ok {
+		return nil
+	}
+	info := v.(RejectInfo)
+	return &info
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"testing"
+)
+
+func TestDropTransaction(t *testing.T) {
+	var chain uint64 = 100
+	if transForMinging == nil {
+		transForMinging = make(map[uint64][]*transInfo)
+	}
+	info := &transInfo{Size: 100}
+	info.Key[0] = 1
+	info.User[0] = 2
+	info.Energy = 1000
+	info.Time = getCoreTimeNow()
+	key := info.Key[:]
+	pushTransInfo(chain, info)
+	if n, _ := ListTransPool(chain, 0, 10); n != 1 {
+		t.Fatal("hope the transaction in mempool:", n)
+	}
+	if _, err := dropTransInfo(chain, key, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dropTransInfo(chain, key, "tester"); err == nil {
+		t.Error("hope not found the dropped transaction")
+	}
+
+	// received from network again(processTransaction)
+	pushTransInfo(chain, info)
+	if n, _ := ListTransPool(chain, 0, 10); n != 0 {
+		t.Error("the dropped transaction is admitted again:", n)
+	}
+	d := GetDropInfo(chain, key)
+	if d == nil || d.Operator != "tester" || d.Resubmit {
+		t.Errorf("error drop info:%#v", d)
+	}
+	if GetRejectInfo(chain, key) != nil {
+		t.Error("the dropped transaction is recorded as rejected")
+	}
+
+	// submitted again by user
+	undropTrans(chain, key)
+	pushTransInfo(chain, info)
+	if n, _ := ListTransPool(chain, 0, 10); n != 1 {
+		t.Error("hope the resubmitted transaction in mempool:", n)
+	}
+	if d = GetDropInfo(chain, key); d == nil || !d.Resubmit {
+		t.Errorf("error drop info:%#v", d)
+	}
+}
+
+func TestShortTransKey(t *testing.T) {
+	if GetDropInfo(101, []byte{1}) != nil || GetRejectInfo(101, []byte{1}) != nil {
+		t.Error("hope not found the short key")
+	}
+}
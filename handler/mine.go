@@ -1,13 +1,13 @@
 package handler
 
 import (
-	"log"
 	"math/rand"
 	"time"
 
 	"github.com/govm-net/govm/conf"
 	core "github.com/govm-net/govm/core"
 	"github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/messages"
 	"github.com/govm-net/govm/runtime"
 	"github.com/govm-net/govm/wallet"
@@ -21,7 +21,8 @@ func init() {
 	myHP = database.NewLRUCache(100 * blockHPNumber)
 }
 
-func newBlockForMining(chain uint64) {
+// newBlockForMining select the transactions by fee per byte(sortTransPool)
+func newBlockForMining(chain uint64) *core.StBlock {
 	var size uint64
 	var trans *transInfo
 	out := make([]core.Hash, 0)
@@ -33,6 +34,7 @@ func newBlockForMining(chain uint64) {
 		block.HashpowerLimit -= 2
 	}
 	// lastID := core.GetLastBlockIndex(chain)
+	sortTransPool(chain)
 	flagTime := time.Now().UnixNano()
 	err := core.CheckTransList(chain, func(chain uint64) core.Hash {
 		if trans != nil && !trans.Key.Empty() {
@@ -69,20 +71,22 @@ func newBlockForMining(chain uint64) {
 	})
 	if err != nil {
 		if trans != nil {
-			log.Printf("error transaction,key:%x,err:%s\n", trans.Key, err)
+			mineLog.Warn("error transaction", logger.KeyChain, chain, logger.KeyTrans, trans.Key, logger.KeyError, err)
+			recordReject(chain, trans.Key[:], err)
 		} else {
-			log.Printf("error transaction,key:nil,err:%s\n", err)
+			mineLog.Warn("error transaction", logger.KeyChain, chain, logger.KeyError, err)
 		}
 	}
 	if len(out) == 0 {
 		setBlockForMining(chain, *block)
-		return
+		return block
 	}
-	log.Println("transaction number for mining:", len(out))
+	mineLog.Debug("transaction number for mining", logger.KeyChain, chain, "number", len(out))
 	core.WriteTransList(chain, out)
 	block.TransListHash = core.GetHashOfTransList(out)
 	SaveTransList(chain, block.TransListHash[:], out)
 	setBlockForMining(chain, *block)
+	return block
 }
 
 func doMining(chain uint64) {
@@ -95,6 +99,10 @@ func doMining(chain uint64) {
 	if !core.IsAdmin(chain, myAddr[:]) {
 		return
 	}
+	// the blocks of devnet are produced on demand(DevnetMine)
+	if core.IsDevnet() {
+		return
+	}
 
 	old := GetBlockForMining(chain)
 	if old != nil {
@@ -104,14 +112,16 @@ func doMining(chain uint64) {
 	}
 
 	block := core.NewBlock(chain, myAddr)
-	if block.Index > 2 && old != nil &&
-		old.Previous == block.Previous && old.Parent == block.Parent {
-		return
-	}
-
-	var count = getCountOfLast10Blocks(chain, block.Index, block.Producer)
-	if count > 2 {
-		return
+	// the private network may have only one admin and no miner
+	if c.GenesisFile == "" {
+		if block.Index > 2 && old != nil &&
+			old.Previous == block.Previous && old.Parent == block.Parent {
+			return
+		}
+		var count = getCountOfLast10Blocks(chain, block.Index, block.Producer)
+		if count > 2 {
+			return
+		}
 	}
 
 	info := popTransInfo(chain)
@@ -119,6 +129,7 @@ func doMining(chain uint64) {
 		pushTransInfo(chain, info)
 		if info.Ops != core.OpsRunApp {
 			err := core.CheckTransaction(chain, info.Key[:])
+			recordReject(chain, info.Key[:], err)
 			if err == nil {
 				core.WriteTransList(1, []core.Hash{info.Key})
 				SaveTransList(chain, info.Key[:], []core.Hash{info.Key})
@@ -127,12 +138,18 @@ func doMining(chain uint64) {
 		}
 	}
 
+	produceBlock(chain, block)
+}
+
+// produceBlock sign the block and save it, return false if fail to sign
+func produceBlock(chain uint64, block *core.StBlock) bool {
+	c := conf.GetConf()
 	for {
 		block.Nonce = rand.Uint64()
 		signData := block.GetSignData()
 		sign := wallet.Sign(c.PrivateKey, signData)
 		if len(sign) == 0 {
-			return
+			return false
 		}
 		if len(c.SignPrefix) > 0 {
 			s := make([]byte, len(c.SignPrefix))
@@ -159,11 +176,11 @@ func doMining(chain uint64) {
 		info.HashPower = rel.HashPower
 		info.PreKey = rel.Previous[:]
 		network.SendInternalMsg(&messages.BaseMsg{Type: messages.BroadcastMsg, Msg: &info})
-		log.Printf("mine one blok,chain:%d,index:%d,hashpower:%d,hp limit:%d,key:%x\n",
-			chain, rel.Index, rel.HashPower, block.HashpowerLimit, rel.Key)
+		mineLog.Info("mine one block", logger.KeyChain, chain, logger.KeyIndex, rel.Index,
+			"hash_power", rel.HashPower, "hp_limit", block.HashpowerLimit, logger.KeyBlock, rel.Key)
 		break
 	}
-
+	return true
 }
 
 // GetMyHashPower get my average hashpower
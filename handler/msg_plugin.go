@@ -7,7 +7,6 @@ import (
 	"expvar"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
 	"time"
@@ -15,6 +14,7 @@ import (
 	"github.com/govm-net/govm/conf"
 	core "github.com/govm-net/govm/core"
 	"github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/messages"
 	"github.com/govm-net/govm/runtime"
 	"github.com/lengzhao/libp2p"
@@ -112,7 +112,7 @@ func (p *MsgPlugin) Receive(ctx libp2p.Event) error {
 
 		data := core.ReadBlockData(msg.Chain, msg.Key)
 		if len(data) == 0 {
-			log.Printf("not found.ReqBlock chain:%d index:%d key:%x\n", msg.Chain, msg.Index, msg.Key)
+			p2pLog.Debug("not found the block", logger.KeyChain, msg.Chain, logger.KeyIndex, msg.Index, logger.KeyBlock, msg.Key)
 			return nil
 		}
 		msgStat.Add("ReqBlock", 1)
@@ -137,7 +137,7 @@ func (p *MsgPlugin) Receive(ctx libp2p.Event) error {
 	case *messages.ReqTransaction:
 		data := core.ReadTransactionData(msg.Chain, msg.Key)
 		if data == nil {
-			log.Printf("not found the transaction,chain:%d,key:%x\n", msg.Chain, msg.Key)
+			p2pLog.Debug("not found the transaction", logger.KeyChain, msg.Chain, logger.KeyTrans, msg.Key)
 			return nil
 		}
 		msgStat.Add("ReqTransaction", 1)
@@ -181,19 +181,19 @@ func processBlock(chain uint64, key, data []byte) (err error) {
 	// 解析block
 	block := core.DecodeBlock(data)
 	if block == nil {
-		log.Printf("error block,chain:%d,key:%x\n", chain, key)
+		syncLog.Warn("fail to decode block", logger.KeyChain, chain, logger.KeyBlock, key)
 		return errors.New("fail to decode")
 	}
 
 	if bytes.Compare(key, block.Key[:]) != 0 {
-		log.Printf("error block key,chain:%d,hope key:%x,key:%x\n", chain, key, block.Key[:])
+		syncLog.Warn("different key of block", logger.KeyChain, chain, logger.KeyBlock, key, "get", block.Key)
 		return errors.New("different key")
 	}
 
 	//first block
 	if chain != block.Chain {
 		if block.Chain != 0 {
-			log.Printf("error chain of block,hope chain:%d,chain:%d,key:%x\n", chain, block.Chain, key)
+			syncLog.Warn("error chain of block", logger.KeyChain, chain, "get", block.Chain, logger.KeyBlock, key)
 			return errors.New("error chain")
 		}
 
@@ -207,7 +207,7 @@ func processBlock(chain uint64, key, data []byte) (err error) {
 
 	now := getCoreTimeNow()
 	if block.Index > 2 && block.Time > now+blockAcceptTime {
-		log.Printf("block too new,chain:%d,key:%x,Producer:%x\n", block.Chain, block.Key, block.Producer)
+		syncLog.Warn("block too new", logger.KeyChain, block.Chain, logger.KeyBlock, block.Key, "producer", block.Producer)
 		return errors.New("too new")
 	}
 
@@ -255,7 +255,7 @@ func processBlock(chain uint64, key, data []byte) (err error) {
 	}
 
 	if rel.Time+tMinute > getCoreTimeNow() && needBroadcastBlock(chain, rel) {
-		log.Printf("BroadcastBlock,chain:%d,index:%d,key:%x\n", chain, rel.Index, rel.Key)
+		p2pLog.Info("broadcast block", logger.KeyChain, chain, logger.KeyIndex, rel.Index, logger.KeyBlock, rel.Key)
 		info := messages.BlockInfo{}
 		info.Chain = chain
 		info.Index = rel.Index
@@ -295,10 +295,10 @@ func processTransaction(chain uint64, key, data []byte) error {
 		return err
 	}
 
-	log.Printf("new transaction.chain%d, key:%x ,osp:%d\n", chain, key, trans.Ops)
+	p2pLog.Info("new transaction", logger.KeyChain, chain, logger.KeyTrans, key, "ops", trans.Ops)
 
 	blockTime := core.GetBlockTime(chain)
-	if blockTime+processTransTime < now {
+	if blockTime+processTransTime < now && !core.IsDevnet() {
 		return nil
 	}
 
@@ -310,6 +310,8 @@ func processTransaction(chain uint64, key, data []byte) error {
 	if rst == nil {
 		saveTransInfo(chain, trans.Key, tInfo)
 		pushTransInfo(chain, &tInfo)
+	} else {
+		recordReject(chain, trans.Key, rst)
 	}
 	msgStat.Add("processTransaction", 1)
 
@@ -328,7 +330,7 @@ func dbRollBack(chain, index uint64, key []byte) error {
 
 	lKey := core.GetTheBlockKey(chain, index)
 	if bytes.Compare(lKey, key) != 0 {
-		log.Printf("dbRollBack error.different key of chain:%d,hope:%x,get:%x\n", chain, key, lKey)
+		syncLog.Warn("dbRollBack,different key", logger.KeyChain, chain, logger.KeyIndex, index, logger.KeyBlock, key, "get", lKey)
 		return errors.New("error block key of the index")
 	}
 	lKey = core.GetTheBlockKey(chain, nIndex)
@@ -337,9 +339,9 @@ func dbRollBack(chain, index uint64, key []byte) error {
 		msgStat.Add("dbRollBack", 1)
 		lKey = core.GetTheBlockKey(chain, nIndex)
 		err = client.Rollback(chain, lKey)
-		log.Printf("dbRollBack,chain:%d,index:%d,key:%x\n", chain, nIndex, lKey)
+		syncLog.Info("dbRollBack", logger.KeyChain, chain, logger.KeyIndex, nIndex, logger.KeyBlock, lKey)
 		if err != nil {
-			log.Println("fail to Rollback.", nIndex, err)
+			syncLog.Error("fail to Rollback", logger.KeyChain, chain, logger.KeyIndex, nIndex, logger.KeyError, err)
 			f := client.GetLastFlag(chain)
 			client.Cancel(chain, f)
 			return err
@@ -421,7 +423,7 @@ func startCheckBlock() {
 func getKeyFromServer(chain, id uint64) []byte {
 	c := conf.GetConf()
 	if c.TrustedServer == "" {
-		log.Println("check block,server is null")
+		syncLog.Warn("check block,server is null")
 		return nil
 	}
 	if !c.CheckBlock {
@@ -433,16 +435,16 @@ func getKeyFromServer(chain, id uint64) []byte {
 	urlStr := fmt.Sprintf("%s/api/v1/1/block/trusted?index=%d", c.TrustedServer, id)
 	resp, err := http.Get(urlStr)
 	if err != nil {
-		log.Println("fail to check block,", err)
+		syncLog.Warn("fail to check block", logger.KeyChain, chain, logger.KeyIndex, id, logger.KeyError, err)
 		return nil
 	}
 	if resp.StatusCode != http.StatusOK {
-		log.Println("error response of check block,", resp.Status)
+		syncLog.Warn("error response of check block", logger.KeyChain, chain, logger.KeyIndex, id, "status", resp.Status)
 		return nil
 	}
 	key, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("check block,fail to read body:", err)
+		syncLog.Warn("check block,fail to read body", logger.KeyChain, chain, logger.KeyIndex, id, logger.KeyError, err)
 		return nil
 	}
 	return key
@@ -459,13 +461,13 @@ func autoRollback(chain, index uint64, key []byte) error {
 		lKey = core.GetTheBlockKey(chain, 0)
 		err = client.Rollback(chain, lKey)
 		if err != nil {
-			log.Println("fail to rollback:", err)
+			syncLog.Error("fail to rollback", logger.KeyChain, chain, logger.KeyBlock, lKey, logger.KeyError, err)
 			return err
 		}
 		nIndex = core.GetLastBlockIndex(chain)
 		count++
 		if count > 10000 {
-			log.Println("rollback too many.", count)
+			syncLog.Error("rollback too many", logger.KeyChain, chain, "count", count)
 			return fmt.Errorf("rollback too many")
 		}
 	}
@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/govm-net/govm/event"
+	"github.com/govm-net/govm/logger"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"sync"
@@ -138,13 +138,13 @@ func (p *NATTPlugin) GetNodes() map[string]string {
 func (p *NATTPlugin) connectNodes() {
 	resp, err := http.Get(fmt.Sprintf("%s/api/v1/nodes", conf.GetConf().TrustedServer))
 	if err != nil {
-		log.Println("NodeNumber=0,fail to get node list,", err)
+		p2pLog.Warn("fail to get node list", logger.KeyError, err)
 		return
 	}
 	defer resp.Body.Close()
 	data, _ := ioutil.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		log.Println("NodeNumber=0,fail to get node list,", string(data))
+		p2pLog.Warn("fail to get node list", "status", resp.Status, "data", string(data))
 		return
 	}
 	if len(data) == 0 {
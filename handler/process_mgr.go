@@ -3,12 +3,13 @@ package handler
 import (
 	"bytes"
 	"errors"
-	"log"
 	"runtime/debug"
 	"sync"
 	"time"
 
+	"github.com/govm-net/govm/conf"
 	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/messages"
 	"github.com/govm-net/govm/runtime"
 )
@@ -48,7 +49,7 @@ func init() {
 
 func timeoutFunc() {
 	if procMgr.stop {
-		log.Println("procMgr.stop")
+		syncLog.Info("procMgr.stop")
 		return
 	}
 	time.AfterFunc(time.Second*10, timeoutFunc)
@@ -65,6 +66,22 @@ func processChains(chain uint64) {
 	processChains(2*chain + 1)
 }
 
+// GetChains get the list of chains which have blocks
+func GetChains() []uint64 {
+	var out []uint64
+	var walk func(chain uint64)
+	walk = func(chain uint64) {
+		if core.GetLastBlockIndex(chain) == 0 {
+			return
+		}
+		out = append(out, chain)
+		walk(2 * chain)
+		walk(2*chain + 1)
+	}
+	walk(1)
+	return out
+}
+
 func getBestBlock(chain, index uint64) TReliability {
 	var relia TReliability
 	ib := ReadIDBlocks(chain, index)
@@ -77,7 +94,7 @@ func getBestBlock(chain, index uint64) TReliability {
 			continue
 		}
 		if index != rel.Index {
-			log.Printf("error index of block,hope:%d,get:%d\n", index, rel.Index)
+			syncLog.Warn("error index of block", logger.KeyChain, chain, logger.KeyIndex, index, "get", rel.Index, logger.KeyBlock, key)
 			rel.Index = 0
 			SaveBlockReliability(chain, key, rel)
 			setIDBlocks(chain, index, it.Key, 0)
@@ -92,18 +109,18 @@ func getBestBlock(chain, index uint64) TReliability {
 		// log.Printf("getBestBlock num:%d,index:%d,hp:%d,lock:%d,key:%x\n", len(ib.Items),
 		// 	index, rel.HashPower, hp, rel.Key)
 		if !rel.Parent.Empty() && !core.BlockOnTheChain(chain/2, rel.Parent[:]) {
-			log.Printf("error block,chain:%d,index:%d,i:%d,hp:%d,key:%x\n",
-				chain, index, i, rel.HashPower, key)
+			syncLog.Warn("error block,not on the chain", logger.KeyChain, chain, logger.KeyIndex, index, "i", i,
+				"hash_power", rel.HashPower, logger.KeyBlock, key)
 			continue
 		}
 		if !rel.LeftChild.Empty() && !core.BlockOnTheChain(chain*2, rel.LeftChild[:]) {
-			log.Printf("error block,chain:%d,index:%d,i:%d,hp:%d,key:%x\n",
-				chain, index, i, rel.HashPower, key)
+			syncLog.Warn("error block,not on the chain", logger.KeyChain, chain, logger.KeyIndex, index, "i", i,
+				"hash_power", rel.HashPower, logger.KeyBlock, key)
 			continue
 		}
 		if !rel.RightChild.Empty() && !core.BlockOnTheChain(chain*2+1, rel.RightChild[:]) {
-			log.Printf("error block,chain:%d,index:%d,i:%d,hp:%d,key:%x\n",
-				chain, index, i, rel.HashPower, key)
+			syncLog.Warn("error block,not on the chain", logger.KeyChain, chain, logger.KeyIndex, index, "i", i,
+				"hash_power", rel.HashPower, logger.KeyBlock, key)
 			continue
 		}
 
@@ -123,10 +140,9 @@ func getBestBlock(chain, index uint64) TReliability {
 		}
 
 		if stat.SelectedCount > 1 || stat.RollbackCount > 1 {
-			log.Printf("getBestBlock,chain:%d,index:%d,key:%x,i:%d,hp:%d,"+
-				"rollback:%d,runTimes:%d,success:%d,selected:%d,hp1:%d\n",
-				chain, index, key, i, rel.HashPower, stat.RollbackCount,
-				stat.RunTimes, stat.RunSuccessCount, stat.SelectedCount, hp)
+			syncLog.Info("getBestBlock", logger.KeyChain, chain, logger.KeyIndex, index, logger.KeyBlock, key, "i", i,
+				"hash_power", rel.HashPower, "rollback", stat.RollbackCount, "run_times", stat.RunTimes,
+				"success", stat.RunSuccessCount, "selected", stat.SelectedCount, "lock", hp)
 			if stat.RollbackCount > 10 {
 				setIDBlocks(chain, index, rel.Key, 0)
 			}
@@ -138,8 +154,8 @@ func getBestBlock(chain, index uint64) TReliability {
 		}
 	}
 	if !relia.Key.Empty() {
-		log.Printf("getBestBlock rst,num:%d,chain:%d,index:%d,hp:%d,key:%x\n", len(ib.Items),
-			chain, index, relia.HashPower, relia.Key)
+		syncLog.Debug("getBestBlock result", "num", len(ib.Items), logger.KeyChain, chain, logger.KeyIndex, index,
+			"hash_power", relia.HashPower, logger.KeyBlock, relia.Key)
 	}
 
 	return relia
@@ -151,7 +167,7 @@ func checkAndRollback(chain, index uint64, key []byte) bool {
 	if cInfo.ParentID > 1 {
 		t0 := core.GetBlockTime(chain / 2)
 		if t0 > t1+blockSyncTime {
-			log.Printf("[warn]chain:%d,index:%d. parent-self.Time:%ds,key:%x\n", chain, index, t0-t1/tSecond, key)
+			syncLog.Warn("the parent chain is ahead", logger.KeyChain, chain, logger.KeyIndex, index, "time", t1, "parent_time", t0, logger.KeyBlock, key)
 			go processEvent(chain / 2)
 			return false
 		}
@@ -159,7 +175,7 @@ func checkAndRollback(chain, index uint64, key []byte) bool {
 	if cInfo.LeftChildID > 1 {
 		t2 := core.GetBlockTime(chain * 2)
 		if t2 > t1+blockSyncTime {
-			log.Printf("[warn]chain:%d,index:%d. leftChild-self.Time:%ds,key:%x\n", chain, index, t2-t1/tSecond, key)
+			syncLog.Warn("the left child chain is ahead", logger.KeyChain, chain, logger.KeyIndex, index, "time", t1, "child_time", t2, logger.KeyBlock, key)
 			go processEvent(chain * 2)
 			return false
 		}
@@ -167,7 +183,7 @@ func checkAndRollback(chain, index uint64, key []byte) bool {
 	if cInfo.RightChildID > 1 {
 		t3 := core.GetBlockTime(chain*2 + 1)
 		if t3 > t1+blockSyncTime {
-			log.Printf("[warn]chain:%d,index:%d. rightChild-self.Time:%ds,key:%x\n", chain, index, t3-t1/tSecond, key)
+			syncLog.Warn("the right child chain is ahead", logger.KeyChain, chain, logger.KeyIndex, index, "time", t1, "child_time", t3, logger.KeyBlock, key)
 			go processEvent(chain*2 + 1)
 			return false
 		}
@@ -183,7 +199,7 @@ func checkOtherChain(chain uint64) error {
 		// parent chain rollback,the block(new chain) is not exist
 		pk := core.GetParentBlockOfChain(chain)
 		if !pk.Empty() && !core.BlockOnTheChain(chain/2, pk[:]) {
-			log.Printf("rollback chain:%d,index:%d,hope:%x on parent chain\n", chain, index, pk)
+			syncLog.Warn("rollback chain,the parent block is not on parent chain", logger.KeyChain, chain, logger.KeyIndex, index, "parent_block", pk)
 			var i uint64
 			ib := IDBlocks{}
 			for i = 2; i < index+1; i++ {
@@ -205,7 +221,7 @@ func checkOtherChain(chain uint64) error {
 	if cInfo.ParentID > 1 {
 		t0 := core.GetBlockTime(chain / 2)
 		if t1 > t0+blockSyncTime {
-			log.Printf("wait chain:%d,index:%d. parent.Time:%d,self.Time:%d\n", chain, index, t0, t1)
+			syncLog.Debug("wait parent chain", logger.KeyChain, chain, logger.KeyIndex, index, "time", t1, "parent_time", t0)
 			go processEvent(chain / 2)
 			return errors.New("wait parent chain")
 		}
@@ -213,7 +229,7 @@ func checkOtherChain(chain uint64) error {
 	if cInfo.LeftChildID > 1 {
 		t2 := core.GetBlockTime(chain * 2)
 		if t1 > t2+blockSyncTime {
-			log.Printf("wait chain:%d,index:%d. leftChild.Time:%d,self.Time:%d\n", chain, index, t2, t1)
+			syncLog.Debug("wait left child chain", logger.KeyChain, chain, logger.KeyIndex, index, "time", t1, "child_time", t2)
 			go processEvent(chain * 2)
 			return errors.New("wait LeftChild chain")
 		}
@@ -221,7 +237,7 @@ func checkOtherChain(chain uint64) error {
 	if cInfo.RightChildID > 1 {
 		t3 := core.GetBlockTime(chain*2 + 1)
 		if t1 > t3+blockSyncTime {
-			log.Printf("wait chain:%d,index:%d. rightChild.Time:%d,self.Time:%d\n", chain, index, t3, t1)
+			syncLog.Debug("wait right child chain", logger.KeyChain, chain, logger.KeyIndex, index, "time", t1, "child_time", t3)
 			go processEvent(chain*2 + 1)
 			return errors.New("wait RightChild chain")
 		}
@@ -237,8 +253,7 @@ func beforeProcBlock(chain uint64, rel TReliability) error {
 	}
 
 	if !rel.Previous.Empty() && !core.IsExistBlock(chain, rel.Previous[:]) {
-		log.Printf("not exist previous,chain:%d,index:%d,key:%x\n",
-			chain, rel.Index-1, rel.Previous)
+		syncLog.Warn("not exist previous", logger.KeyChain, chain, logger.KeyIndex, rel.Index-1, logger.KeyBlock, rel.Previous)
 		rel.Index = 0
 		SaveBlockReliability(chain, rel.Key[:], rel)
 		setIDBlocks(chain, rel.Index, rel.Key, 0)
@@ -263,7 +278,7 @@ func beforeProcBlock(chain uint64, rel TReliability) error {
 	}
 
 	if checkAndRollback(chain, id, preKey) {
-		log.Printf("dbRollBack block. index:%d,key:%x,next block:%x\n", rel.Index, preKey, rel.Key)
+		syncLog.Info("dbRollBack block", logger.KeyChain, chain, logger.KeyIndex, rel.Index, logger.KeyBlock, preKey, "next_block", rel.Key)
 		setIDBlocks(chain, rel.Index-1, rel.Previous, rel.HashPower)
 	}
 
@@ -278,15 +293,14 @@ func processEvent(chain uint64) {
 		return
 	}
 	if procMgr.stop {
-		log.Println("procMgr.stop")
+		syncLog.Info("procMgr.stop")
 		return
 	}
 
 	defer func() {
 		e := recover()
 		if e != nil {
-			log.Println("something error,", e)
-			log.Println(string(debug.Stack()))
+			syncLog.Error("fail to process event", logger.KeyChain, chain, logger.KeyError, e, "stack", string(debug.Stack()))
 		}
 	}()
 
@@ -321,7 +335,7 @@ func processEvent(chain uint64) {
 	// check child chain
 	err := checkOtherChain(chain)
 	if err != nil {
-		log.Printf("checkOtherChain,rollback,chain:%d,err:%s\n", chain, err)
+		syncLog.Warn("checkOtherChain,rollback", logger.KeyChain, chain, logger.KeyError, err)
 		lk := core.GetTheBlockKey(chain, index)
 		dbRollBack(chain, index, lk)
 		return
@@ -334,7 +348,7 @@ func processEvent(chain uint64) {
 		relia = getBestBlock(chain, index)
 		nowKey := core.GetTheBlockKey(chain, index)
 		if !relia.Key.Empty() && bytes.Compare(relia.Key[:], nowKey) != 0 {
-			log.Printf("dbRollBack block. index:%d,key:%x,next block:%x\n", index, nowKey, relia.Key)
+			syncLog.Info("dbRollBack block", logger.KeyChain, chain, logger.KeyIndex, index, logger.KeyBlock, nowKey, "next_block", relia.Key)
 			checkAndRollback(chain, index, nowKey)
 			return
 		}
@@ -342,7 +356,23 @@ func processEvent(chain uint64) {
 		if t+core.GetBlockInterval(chain) >= now {
 			return
 		}
-		log.Printf("no next block key,chain:%d,index:%d\n", chain, index+1)
+		// the blocks of devnet are produced on demand, not rollback
+		if core.IsDevnet() {
+			return
+		}
+		// the admin of private network produces the next block, not rollback,
+		// check it again after the block interval
+		if conf.GetConf().GenesisFile != "" {
+			var addr core.Address
+			runtime.Decode(conf.GetConf().WalletAddr, &addr)
+			if core.IsAdmin(chain, addr[:]) {
+				doMining(chain)
+				interval := time.Duration(core.GetBlockInterval(chain)) * time.Millisecond
+				time.AfterFunc(interval, func() { processEvent(chain) })
+				return
+			}
+		}
+		syncLog.Debug("no next block key", logger.KeyChain, chain, logger.KeyIndex, index+1)
 		procMgr.mu.Lock()
 		procTime := procMgr.procTime[chain]
 		procMgr.mu.Unlock()
@@ -374,10 +404,15 @@ func processEvent(chain uint64) {
 		return
 	}
 	stat.RunTimes++
-	log.Printf("process block,chain:%d,index:%d,key:%x\n", chain, relia.Index, relia.Key)
+	syncLog.Info("process block", logger.KeyChain, chain, logger.KeyIndex, relia.Index, logger.KeyBlock, relia.Key)
 	err = core.ProcessBlockOfChain(chain, relia.Key[:])
+	if runtime.IsRetryError(err) {
+		syncLog.Warn("fail to process block,retry later", logger.KeyChain, chain, logger.KeyIndex, index+1, logger.KeyBlock, relia.Key, logger.KeyError, err)
+		SaveBlockRunStat(chain, relia.Key[:], stat)
+		return
+	}
 	if err != nil {
-		log.Printf("fail to process block,chain:%d,index:%d,key:%x,error:%s\n", chain, index+1, relia.Key, err)
+		syncLog.Error("fail to process block", logger.KeyChain, chain, logger.KeyIndex, index+1, logger.KeyBlock, relia.Key, logger.KeyError, err)
 		SaveBlockRunStat(chain, relia.Key[:], stat)
 		setIDBlocks(chain, relia.Index, relia.Key, 0)
 		relia.Ready = false
@@ -386,6 +421,7 @@ func processEvent(chain uint64) {
 		return
 	}
 	setBlockProducer(chain, relia.Index, relia.Producer)
+	sendNewHead(chain, relia)
 
 	procMgr.mu.Lock()
 	procMgr.procTime[chain] = now
@@ -398,7 +434,7 @@ func processEvent(chain uint64) {
 		return
 	}
 
-	if relia.Time+2*tMinute > now {
+	if relia.Time+2*tMinute > now && !core.IsDevnet() {
 		doMining(chain)
 		go newBlockForMining(chain)
 	}
@@ -420,7 +456,7 @@ func writeFirstBlockToChain(chain uint64) {
 	var k core.Hash
 	runtime.Decode(key, &k)
 	setIDBlocks(chain, 1, k, 1000)
-	log.Println("new chain:", chain)
+	syncLog.Info("new chain", logger.KeyChain, chain)
 	go processEvent(chain)
 }
 
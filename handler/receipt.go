@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/govm-net/govm/messages"
+	"github.com/govm-net/govm/runtime"
+)
+
+const ldbReceipt = "receipt" //transKey:Receipt
+
+// status of receipt
+const (
+	ReceiptSuccess = "success"
+	ReceiptFailed  = "failed"
+)
+
+// Receipt the result of transaction
+type Receipt struct {
+	Chain      uint64     `json:"chain,omitempty"`
+	TransKey   string     `json:"trans_key,omitempty"`
+	BlockIndex uint64     `json:"block_index,omitempty"`
+	BlockKey   string     `json:"block_key,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	EnergyUsed uint64     `json:"energy_used"`
+	Error      string     `json:"error,omitempty"`
+	Events     []EventLog `json:"events,omitempty"`
+}
+
+func addEnergyUsed(msg *messages.AppRun) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	p := pending[msg.Chain]
+	if p == nil || p.receipt == nil || bytes.Compare(p.block, msg.Block) != 0 {
+		return
+	}
+	p.receipt.EnergyUsed += msg.Used
+}
+
+func saveReceipt(chain, index uint64, r *Receipt) {
+	r.BlockIndex = index
+	if len(r.Events) > 0 {
+		r.BlockKey = r.Events[0].BlockKey
+	}
+	for i := range r.Events {
+		r.Events[i].BlockIndex = index
+	}
+	key, _ := hex.DecodeString(r.TransKey)
+	d, _ := json.Marshal(r)
+	elog.LSet(chain, ldbReceipt, key, d)
+}
+
+// saveFailedReceipt save the receipt of the transaction rejected by CheckTransaction,
+// it will not replace the receipt of the transaction in block
+func saveFailedReceipt(chain uint64, key []byte, err error) {
+	if elog == nil || runtime.IsRetryError(err) {
+		return
+	}
+	if old := GetReceipt(chain, key); old != nil && old.Status == ReceiptSuccess {
+		return
+	}
+	r := Receipt{Chain: chain, TransKey: hex.EncodeToString(key)}
+	r.Status = ReceiptFailed
+	r.Error = err.Error()
+	if ae, ok := err.(*runtime.TAppError); ok {
+		r.EnergyUsed = ae.Used
+	}
+	d, _ := json.Marshal(r)
+	elog.LSet(chain, ldbReceipt, key, d)
+}
+
+// GetReceipt get the receipt of the transaction
+func GetReceipt(chain uint64, key []byte) *Receipt {
+	if elog == nil {
+		return nil
+	}
+	d := elog.LGet(chain, ldbReceipt, key)
+	if len(d) == 0 {
+		return nil
+	}
+	var out Receipt
+	err := json.Unmarshal(d, &out)
+	if err != nil {
+		return nil
+	}
+	return &out
+}
@@ -5,10 +5,10 @@ import (
 	"encoding/hex"
 	"expvar"
 	"fmt"
-	"log"
 	"runtime/debug"
 
 	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/messages"
 	"github.com/lengzhao/libp2p"
 )
@@ -140,8 +140,8 @@ func (p *SyncPlugin) syncDepend(ctx libp2p.Event, chain uint64, key []byte) {
 	defer func() {
 		err := recover()
 		if err != nil {
-			log.Printf("syncDepend error,chain:%d,key:%x,err:%s\n", chain, key, err)
-			log.Println(string(debug.Stack()))
+			syncLog.Error("syncDepend error", logger.KeyChain, chain, logger.KeyBlock, key,
+				logger.KeyError, err, "stack", string(debug.Stack()))
 		}
 	}()
 
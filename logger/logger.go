@@ -0,0 +1,306 @@
+// Package logger the structured logger of node, every record is one line of json.
+//
+// the records are tagged with the subsystem(sync, mining, runtime, api, p2p...),
+// the level of every subsystem can be changed at runtime:
+//
+//	log := logger.New(logger.SubSync)
+//	log.With(logger.KeyChain, chain).Info("process block", logger.KeyIndex, index, logger.KeyBlock, key)
+//
+// output:
+//
+//	{"time":"2020-01-02T15:04:05.000+08:00","level":"info","subsys":"sync","caller":"sync.go:10","msg":"process block","chain":1,"index":10,"block":"0a0b..."}
+package logger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level the level of log
+type Level int32
+
+// the levels of log
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelOff
+)
+
+var levelNames = []string{"debug", "info", "warn", "error", "off"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelOff {
+		return fmt.Sprintf("level(%d)", l)
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parse the name of level
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		name = "warn"
+	}
+	for i, n := range levelNames {
+		if n == name {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level:%s", name)
+}
+
+// subsystems of node
+const (
+	SubNode    = "node"
+	SubSync    = "sync"
+	SubMining  = "mining"
+	SubRuntime = "runtime"
+	SubAPI     = "api"
+	SubP2P     = "p2p"
+	SubCore    = "core"
+	SubConf    = "conf"
+)
+
+// the keys of common fields
+const (
+	KeyChain = "chain"
+	KeyIndex = "index"
+	KeyBlock = "block"
+	KeyTrans = "trans"
+	KeyApp   = "app"
+	KeyPeer  = "peer"
+	KeyError = "error"
+)
+
+type subsystem struct {
+	level int32
+	// set the level is set by SetLevel, otherwise it follows the default level
+	set int32
+}
+
+var (
+	mu       sync.Mutex
+	out      io.Writer = os.Stderr
+	defLevel           = int32(LevelInfo)
+	subs               = make(map[string]*subsystem)
+)
+
+func getSubsystem(name string) *subsystem {
+	mu.Lock()
+	defer mu.Unlock()
+	s := subs[name]
+	if s == nil {
+		s = &subsystem{level: atomic.LoadInt32(&defLevel)}
+		subs[name] = s
+	}
+	return s
+}
+
+// SetOutput set the output of all loggers, nil means discard
+func SetOutput(w io.Writer) {
+	if w == nil {
+		w = ioutil.Discard
+	}
+	mu.Lock()
+	out = w
+	mu.Unlock()
+}
+
+// SetLevel set the level of the subsystem, set the default level of all subsystems if subsys is empty,
+// the subsystems with the level set by name are not changed by the default level
+func SetLevel(subsys string, level Level) {
+	if subsys != "" {
+		s := getSubsystem(subsys)
+		atomic.StoreInt32(&s.level, int32(level))
+		atomic.StoreInt32(&s.set, 1)
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	atomic.StoreInt32(&defLevel, int32(level))
+	for _, s := range subs {
+		if atomic.LoadInt32(&s.set) == 0 {
+			atomic.StoreInt32(&s.level, int32(level))
+		}
+	}
+}
+
+// ResetLevel the level of the subsystem follows the default level
+func ResetLevel(subsys string) {
+	s := getSubsystem(subsys)
+	atomic.StoreInt32(&s.set, 0)
+	atomic.StoreInt32(&s.level, atomic.LoadInt32(&defLevel))
+}
+
+// GetLevel get the level of the subsystem, return the default level if subsys is empty
+func GetLevel(subsys string) Level {
+	if subsys == "" {
+		return Level(atomic.LoadInt32(&defLevel))
+	}
+	return Level(atomic.LoadInt32(&getSubsystem(subsys).level))
+}
+
+// Levels return the level of all known subsystems
+func Levels() map[string]string {
+	mu.Lock()
+	defer mu.Unlock()
+	rst := make(map[string]string)
+	for name, s := range subs {
+		rst[name] = Level(atomic.LoadInt32(&s.level)).String()
+	}
+	return rst
+}
+
+// Subsystems return the names of all known subsystems
+func Subsystems() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	var rst []string
+	for name := range subs {
+		rst = append(rst, name)
+	}
+	sort.Strings(rst)
+	return rst
+}
+
+// Logger the logger of subsystem, it is safe for concurrent use
+type Logger struct {
+	subsys string
+	s      *subsystem
+	fields []interface{}
+}
+
+// New new logger of the subsystem
+func New(subsys string) *Logger {
+	return &Logger{subsys: subsys, s: getSubsystem(subsys)}
+}
+
+// With return the logger with the fields(key and value pairs), they are written in every record
+func (l *Logger) With(kv ...interface{}) *Logger {
+	nl := *l
+	nl.fields = append(append([]interface{}{}, l.fields...), kv...)
+	return &nl
+}
+
+// Enabled return true if the level is enabled
+func (l *Logger) Enabled(level Level) bool {
+	return level >= Level(atomic.LoadInt32(&l.s.level)) && level < LevelOff
+}
+
+// Debug write the record of debug level
+func (l *Logger) Debug(msg string, kv ...interface{}) {
+	l.output(2, LevelDebug, msg, kv)
+}
+
+// Info write the record of info level
+func (l *Logger) Info(msg string, kv ...interface{}) {
+	l.output(2, LevelInfo, msg, kv)
+}
+
+// Warn write the record of warn level
+func (l *Logger) Warn(msg string, kv ...interface{}) {
+	l.output(2, LevelWarn, msg, kv)
+}
+
+// Error write the record of error level
+func (l *Logger) Error(msg string, kv ...interface{}) {
+	l.output(2, LevelError, msg, kv)
+}
+
+// Log write the record, skip is the number of stack frames to ascend for the caller
+func (l *Logger) Log(skip int, level Level, msg string, kv ...interface{}) {
+	l.output(skip+2, level, msg, kv)
+}
+
+func (l *Logger) output(skip int, level Level, msg string, kv []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	caller := ""
+	if _, file, line, ok := runtime.Caller(skip); ok {
+		caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	l.write(level, caller, msg, kv)
+}
+
+func (l *Logger) write(level Level, caller, msg string, kv []interface{}) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(recordPrefix)
+	writeValue(buf, time.Now().Format("2006-01-02T15:04:05.000Z07:00"))
+	buf.WriteString(`,"level":`)
+	writeValue(buf, level.String())
+	buf.WriteString(`,"subsys":`)
+	writeValue(buf, l.subsys)
+	if caller != "" {
+		buf.WriteString(`,"caller":`)
+		writeValue(buf, caller)
+	}
+	buf.WriteString(`,"msg":`)
+	writeValue(buf, msg)
+	writeFields(buf, l.fields)
+	writeFields(buf, kv)
+	buf.WriteString("}\n")
+
+	mu.Lock()
+	defer mu.Unlock()
+	out.Write(buf.Bytes())
+}
+
+func writeFields(buf *bytes.Buffer, kv []interface{}) {
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		buf.WriteByte(',')
+		writeValue(buf, key)
+		buf.WriteByte(':')
+		if i+1 < len(kv) {
+			writeValue(buf, kv[i+1])
+		} else {
+			buf.WriteString(`"(missing)"`)
+		}
+	}
+}
+
+// writeValue write the value as json, the []byte(and the array of byte) is written as hex string
+func writeValue(buf *bytes.Buffer, v interface{}) {
+	switch val := v.(type) {
+	case []byte:
+		v = hex.EncodeToString(val)
+	case [][]byte:
+		lst := make([]string, len(val))
+		for i, it := range val {
+			lst[i] = hex.EncodeToString(it)
+		}
+		v = lst
+	case error:
+		v = val.Error()
+	case fmt.Stringer:
+		v = val.String()
+	case nil:
+	default:
+		if d, ok := hexArray(v); ok {
+			v = d
+		}
+	}
+	d, err := json.Marshal(v)
+	if err != nil {
+		d, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(d)
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	SetOutput(buf)
+	defer SetOutput(nil)
+
+	l := New("test_sync").With(KeyChain, 1)
+	l.Debug("hidden")
+	l.Info("process block", KeyIndex, 10, KeyBlock, [2]byte{1, 2}, KeyTrans, []byte{3})
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal("error json:", buf.String(), err)
+	}
+	hope := map[string]interface{}{"level": "info", "subsys": "test_sync", "msg": "process block",
+		"chain": 1.0, "index": 10.0, "block": "0102", "trans": "03", "caller": "logger_test.go:17"}
+	for k, v := range hope {
+		if rec[k] != v {
+			t.Errorf("different %s,hope:%v,get:%v", k, v, rec[k])
+		}
+	}
+
+	buf.Reset()
+	SetLevel("test_sync", LevelDebug)
+	SetLevel("", LevelError)
+	l.Debug("shown")
+	New("test_other").Warn("hidden")
+	if GetLevel("test_other") != LevelError || Levels()["test_sync"] != "debug" {
+		t.Error("error levels:", Levels())
+	}
+	ResetLevel("test_sync")
+	l.Debug("hidden")
+	SetLevel("", LevelInfo)
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 || !bytes.Contains(buf.Bytes(), []byte(`"msg":"shown"`)) {
+		t.Error("error output:", buf.String())
+	}
+}
+
+func TestWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	SetOutput(buf)
+	defer SetOutput(nil)
+
+	w := NewWriter(New("test_std").With(KeyApp, "a1"), LevelWarn)
+	std := log.New(w, "", log.LstdFlags|log.Lshortfile)
+	std.Println("fail to open db")
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal("error json:", buf.String(), err)
+	}
+	if rec["msg"] != "fail to open db" || rec["level"] != "warn" || rec["caller"] != "logger_test.go:53" || rec["app"] != "a1" {
+		t.Error("error record:", buf.String())
+	}
+
+	// the record of sub process
+	buf.Reset()
+	line := `{"time":"2020-01-02T15:04:05.000Z","level":"info","subsys":"runtime","msg":"run app"}`
+	w.Write([]byte(line + "\n"))
+	if buf.String() != line+"\n" {
+		t.Error("error record:", buf.String())
+	}
+}
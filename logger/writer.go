@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+// hexArray return the hex string of the array of byte, such as Hash and Address
+func hexArray(v interface{}) (string, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Array || rv.Type().Elem().Kind() != reflect.Uint8 {
+		return "", false
+	}
+	d := make([]byte, rv.Len())
+	reflect.Copy(reflect.ValueOf(d), rv)
+	return hex.EncodeToString(d), true
+}
+
+const recordPrefix = `{"time":`
+
+var (
+	// the prefix of stdlib log, log.LstdFlags|log.Lmicroseconds
+	stdTimePrefix = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? `)
+	// the prefix of stdlib log, log.Lshortfile
+	stdFilePrefix = regexp.MustCompile(`^([\w.\-]+\.go:\d+): `)
+)
+
+// Writer convert every line to the record of the logger,
+// it is used as the output of stdlib log and the output of sub process(such as app worker),
+// the line which is already the record(json) is written directly
+type Writer struct {
+	l     *Logger
+	level Level
+	mu    sync.Mutex
+	buf   []byte
+}
+
+// NewWriter new writer of the logger, the lines are written with the level
+func NewWriter(l *Logger, level Level) *Writer {
+	return &Writer{l: l, level: level}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+func (w *Writer) writeLine(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 || !w.l.Enabled(w.level) {
+		return
+	}
+	if bytes.HasPrefix(line, []byte(recordPrefix)) && json.Valid(line) {
+		rec := append(append([]byte{}, line...), '\n')
+		mu.Lock()
+		out.Write(rec)
+		mu.Unlock()
+		return
+	}
+	line = stdTimePrefix.ReplaceAll(line, nil)
+	caller := ""
+	if m := stdFilePrefix.FindSubmatch(line); m != nil {
+		caller = string(m[1])
+		line = line[len(m[0]):]
+	}
+	w.l.write(w.level, caller, string(line), nil)
+}
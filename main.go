@@ -8,12 +8,16 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/govm-net/govm/api"
 	"github.com/govm-net/govm/conf"
 	"github.com/govm-net/govm/database"
 	"github.com/govm-net/govm/handler"
+	"github.com/govm-net/govm/logger"
+	"github.com/govm-net/govm/runtime"
 	"github.com/govm-net/govm/wallet"
 	"github.com/lengzhao/libp2p/crypto"
 	"github.com/lengzhao/libp2p/network"
@@ -22,10 +26,9 @@ import (
 )
 
 func main() {
-	log.SetFlags(log.Lshortfile | log.LstdFlags)
 	c := conf.GetConf()
 	if c.SaveLog {
-		log.SetOutput(&lumberjack.Logger{
+		logger.SetOutput(&lumberjack.Logger{
 			Filename:   "./log/govm.log",
 			MaxSize:    50, // megabytes
 			MaxBackups: 5,
@@ -33,29 +36,58 @@ func main() {
 			Compress:   true, // disabled by default
 		})
 	} else {
-		log.SetOutput(ioutil.Discard)
+		logger.SetOutput(ioutil.Discard)
 	}
+	// the stdlib log is written as the record of node
+	log.SetFlags(log.Lshortfile)
+	log.SetOutput(logger.NewWriter(logger.New(logger.SubNode), logger.LevelInfo))
+	setLogLevel(c)
+	conf.OnReload(reloadLogLevel)
+	go watchReload()
 	database.ChangeClientNumber(10)
+	netID, err := handler.GenesisNetID()
+	if err != nil {
+		fmt.Println("fail to load the genesis file:", c.GenesisFile, err)
+		os.Exit(2)
+	}
 	client := database.GetClient()
 	val := client.Get(1, []byte("info"), []byte("net"))
 	if len(val) == 0 {
-		err := client.Set(1, []byte("info"), []byte("net"), []byte(c.NetID))
+		err := client.Set(1, []byte("info"), []byte("net"), []byte(netID))
 		if err != nil {
-			fmt.Println("fail to set database,make sure the database server running.", err)
+			fmt.Println("fail to set database,make sure the database server running(or set db_type to embedded).", err)
 			os.Exit(2)
 		}
-	} else if string(val) != c.NetID {
-		fmt.Println("different net id,hope:", c.NetID, ", get:", val)
+	} else if string(val) != netID {
+		fmt.Println("different net id,hope:", netID, ", get:", string(val))
 		os.Exit(3)
 	}
 
+	if err := conf.CheckPassword(); err != nil {
+		fmt.Println(err)
+		os.Exit(4)
+	}
 	conf.LoadWallet(c.WalletFile, c.Password)
 	// startHTTPServer
 	{
 		addr := fmt.Sprintf("%s:%d", c.HTTPAddress, c.HTTPPort)
 		router := api.NewRouter()
+		api.SetAuditOutput(&lumberjack.Logger{
+			Filename:   c.AuditLog,
+			MaxSize:    50, // megabytes
+			MaxBackups: 10,
+			MaxAge:     90, //days
+		})
+		if len(c.APIKeys) == 0 && c.AdminToken == "" && c.HTTPAddress != "127.0.0.1" && c.HTTPAddress != "localhost" {
+			fmt.Println("warning: all apis are open, set api_keys of conf.json, http address:", c.HTTPAddress)
+		}
 		go func() {
-			err := http.ListenAndServe(addr, router)
+			var err error
+			if c.TLSCertFile != "" {
+				err = http.ListenAndServeTLS(addr, c.TLSCertFile, c.TLSKeyFile, router)
+			} else {
+				err = http.ListenAndServe(addr, router)
+			}
 			if err != nil {
 				fmt.Println("fail to http Listen:", addr, err)
 				os.Exit(2)
@@ -71,7 +103,10 @@ func main() {
 		os.Exit(2)
 	}
 
-	{
+	// devnet is a local network, no bootstrap and discovery
+	if c.Devnet {
+		fmt.Println("devnet mode, net id:", c.NetID)
+	} else {
 		data, err := ioutil.ReadFile("./conf/bootstrap.json")
 		if err == nil {
 			var peers []string
@@ -82,12 +117,12 @@ func main() {
 				n.RegistPlugin(b)
 			}
 		}
+		n.RegistPlugin(new(plugins.DiscoveryPlugin))
 	}
 
-	n.RegistPlugin(new(plugins.DiscoveryPlugin))
 	n.RegistPlugin(new(plugins.Broadcast))
 	key := loadNodeKey()
-	rk := wallet.EcdsaKey{Type: c.NetID, NeedVerify: c.VerifyNetData}
+	rk := wallet.EcdsaKey{Type: netID, NeedVerify: c.VerifyNetData}
 	cp := crypto.NewMgr()
 	cp.Register(&rk)
 	cp.SetPrivKey(rk.GetType(), key)
@@ -95,31 +130,107 @@ func main() {
 	n.RegistPlugin(new(handler.MsgPlugin))
 	n.RegistPlugin(new(handler.InternalPlugin))
 	n.RegistPlugin(new(handler.SyncPlugin))
-	n.RegistPlugin(new(handler.NATTPlugin))
+	if !c.Devnet {
+		n.RegistPlugin(new(handler.NATTPlugin))
+	}
 
-	err := n.Listen(c.ServerHost)
+	err = n.Listen(c.ServerHost)
 	if err != nil {
 		log.Println("fail to listen:", c.ServerHost, err)
 	}
 	n.Close()
+	runtime.CloseWorkers()
 	log.Println("wait to exit(3s)")
 	time.Sleep(3 * time.Second)
 }
 
+// setLogLevel set the level of log by the configure
+func setLogLevel(c conf.TConfig) {
+	if c.LogLevel != "" {
+		level, err := logger.ParseLevel(c.LogLevel)
+		if err != nil {
+			fmt.Println("error log_level of configure,", err)
+		}
+		logger.SetLevel("", level)
+	}
+	for subsys, name := range c.LogLevels {
+		level, err := logger.ParseLevel(name)
+		if err != nil {
+			fmt.Println("error log_levels of configure,", subsys, err)
+			continue
+		}
+		logger.SetLevel(subsys, level)
+	}
+}
+
+// reloadLogLevel reset the level of log if it is changed by reloading configure
+func reloadLogLevel(old, c conf.TConfig, changed []string) {
+	var change bool
+	for _, it := range changed {
+		if it == "log_level" || it == "log_levels" {
+			change = true
+		}
+	}
+	if !change {
+		return
+	}
+	for subsys := range old.LogLevels {
+		if _, ok := c.LogLevels[subsys]; !ok {
+			logger.ResetLevel(subsys)
+		}
+	}
+	if c.LogLevel == "" {
+		logger.SetLevel("", logger.LevelInfo)
+	}
+	setLogLevel(c)
+}
+
+// watchReload reload the configure when receive SIGHUP
+func watchReload() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	for range ch {
+		rst, err := conf.Reload()
+		if err != nil {
+			fmt.Println("fail to reload configure,", err)
+			continue
+		}
+		fmt.Println("reload configure, changed:", rst.Changed, ", need restart:", rst.NeedRestart)
+	}
+}
+
 func loadNodeKey() []byte {
-	if !conf.GetConf().SaveNodeInfo {
+	c := conf.GetConf()
+	if !c.SaveNodeInfo {
 		return wallet.NewPrivateKey()
 	}
 	const (
 		nodeKeyFile = "./conf/node_key.dat"
-		passwd      = "10293847561029384756"
+		// the node key is the identity of p2p, not the password of wallet,
+		// it is not changed with the password of wallet
+		nodeKeyPasswd = "10293847561029384756"
 	)
-	w, err := wallet.LoadWallet(nodeKeyFile, passwd)
-	if err != nil {
+	var w wallet.TWallet
+	_, err := os.Stat(nodeKeyFile)
+	if os.IsNotExist(err) {
 		w.Key = wallet.NewPrivateKey()
-		pubKey := wallet.GetPublicKey(w.Key)
-		addr := wallet.PublicKeyToAddress(pubKey, wallet.EAddrTypeDefault)
-		wallet.SaveWallet(nodeKeyFile, passwd, addr, w.Key, nil)
+	} else {
+		w, err = wallet.LoadWallet(nodeKeyFile, nodeKeyPasswd)
+		if err != nil {
+			// it was encrypted by the password of wallet
+			w, err = wallet.LoadWallet(nodeKeyFile, c.Password)
+			w.Version = 0
+		}
+		if err != nil {
+			fmt.Println("fail to load the node key, fix or remove the file:", nodeKeyFile, err)
+			os.Exit(2)
+		}
+	}
+	if w.Version == wallet.WalletVersion {
+		return w.Key
 	}
+	pubKey := wallet.GetPublicKey(w.Key)
+	addr := wallet.PublicKeyToAddress(pubKey, wallet.EAddrTypeDefault)
+	wallet.SaveWallet(nodeKeyFile, nodeKeyPasswd, addr, w.Key, nil)
 	return w.Key
 }
@@ -83,6 +83,47 @@ type MinerInfo struct {
 	Online bool
 }
 
+// NewHead the block is processed
+type NewHead struct {
+	BaseMsg
+	Chain     uint64
+	Index     uint64
+	Key       []byte
+	Time      uint64
+	Producer  []byte
+	TransList [][]byte
+}
+
+// AppEvent event of app(runtime.Event)
+type AppEvent struct {
+	BaseMsg
+	Chain  uint64
+	Block  []byte
+	App    []byte
+	Struct string
+	Event  string
+	Params [][]byte
+}
+
+// BlockEvents the app events of the block, it is sent after the block is processed(NewHead),
+// the events of the failed or replaced block are not included
+type BlockEvents struct {
+	BaseMsg
+	Chain  uint64
+	Index  uint64
+	Key    []byte
+	Events []*AppEvent
+}
+
+// AppRun the app finished, Used is the energy used by the app
+type AppRun struct {
+	BaseMsg
+	Chain uint64
+	Block []byte
+	App   []byte
+	Used  uint64
+}
+
 // Internal msg keys
 const (
 	BroadcastMsg = "broadcast"
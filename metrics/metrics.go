@@ -0,0 +1,241 @@
+// Package metrics write the metrics of node in the prometheus text exposition format(version 0.0.4).
+// the gauges are collected by callback when scraped, the histograms are observed by the caller.
+package metrics
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ContentType the content type of the output
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Sample one value of metric, Labels is the list of name and value pairs
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// Collector write the metrics to w
+type Collector interface {
+	Collect(w io.Writer)
+}
+
+type gaugeFunc struct {
+	name string
+	help string
+	typ  string
+	cb   func() []Sample
+}
+
+var (
+	mu         sync.Mutex
+	collectors []Collector
+	// ExportExpvar export the int values of expvar.Map as counters
+	ExportExpvar = true
+)
+
+// Register register the collector
+func Register(c Collector) {
+	mu.Lock()
+	defer mu.Unlock()
+	collectors = append(collectors, c)
+}
+
+// NewGaugeFunc register the gauge, the samples are got from cb when scraped
+func NewGaugeFunc(name, help string, cb func() []Sample) {
+	Register(&gaugeFunc{name, help, "gauge", cb})
+}
+
+// NewCounterFunc register the counter, the samples are got from cb when scraped
+func NewCounterFunc(name, help string, cb func() []Sample) {
+	Register(&gaugeFunc{name, help, "counter", cb})
+}
+
+func (g *gaugeFunc) Collect(w io.Writer) {
+	writeHead(w, g.name, g.help, g.typ)
+	for _, s := range g.cb() {
+		writeSample(w, g.name, s.Labels, s.Value)
+	}
+}
+
+// Histogram the histogram with labels
+type Histogram struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	series  map[string]*histSeries
+}
+
+type histSeries struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// DefBuckets the default buckets of histogram(seconds)
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// NewHistogram create and register the histogram, buckets is DefBuckets if it is empty
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+	h := &Histogram{name: name, help: help, labels: labels, buckets: b}
+	h.series = make(map[string]*histSeries)
+	Register(h)
+	return h
+}
+
+// Observe add one value, the number of values must be same as labels
+func (h *Histogram) Observe(v float64, values ...string) {
+	if len(values) != len(h.labels) {
+		return
+	}
+	key := strings.Join(values, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histSeries{values: values, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+// Collect write the histogram
+func (h *Histogram) Collect(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	writeHead(w, h.name, h.help, "histogram")
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := h.series[k]
+		labels := make([]string, 0, 2*len(h.labels)+2)
+		for i, n := range h.labels {
+			labels = append(labels, n, s.values[i])
+		}
+		for i, b := range h.buckets {
+			writeSample(w, h.name+"_bucket", append(labels, "le", formatFloat(b)), float64(s.counts[i]))
+		}
+		writeSample(w, h.name+"_bucket", append(labels, "le", "+Inf"), float64(s.count))
+		writeSample(w, h.name+"_sum", labels, s.sum)
+		writeSample(w, h.name+"_count", labels, float64(s.count))
+	}
+}
+
+// WriteTo write all metrics to w
+func WriteTo(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	mu.Lock()
+	list := make([]Collector, len(collectors))
+	copy(list, collectors)
+	mu.Unlock()
+	for _, c := range list {
+		c.Collect(bw)
+	}
+	if ExportExpvar {
+		writeExpvar(bw)
+	}
+	return bw.Flush()
+}
+
+// writeExpvar write the int values of expvar.Map, such as expvar "restful" => govm_restful_total{name="xxx"}
+func writeExpvar(w io.Writer) {
+	expvar.Do(func(kv expvar.KeyValue) {
+		m, ok := kv.Value.(*expvar.Map)
+		if !ok {
+			return
+		}
+		name := "govm_" + sanitize(kv.Key) + "_total"
+		var head bool
+		m.Do(func(it expvar.KeyValue) {
+			v, ok := it.Value.(*expvar.Int)
+			if !ok {
+				return
+			}
+			if !head {
+				writeHead(w, name, "expvar "+kv.Key, "counter")
+				head = true
+			}
+			writeSample(w, name, []string{"name", it.Key}, float64(v.Value()))
+		})
+	})
+}
+
+func writeHead(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escape(help, false))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+func writeSample(w io.Writer, name string, labels []string, v float64) {
+	io.WriteString(w, name)
+	if len(labels) > 1 {
+		io.WriteString(w, "{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				io.WriteString(w, ",")
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", sanitize(labels[i]), escape(labels[i+1], true))
+		}
+		io.WriteString(w, "}")
+	}
+	fmt.Fprintf(w, " %s\n", formatFloat(v))
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return fmt.Sprint(v)
+}
+
+func escape(s string, quote bool) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	if quote {
+		s = strings.Replace(s, `"`, `\"`, -1)
+	}
+	return s
+}
+
+// sanitize replace the invalid char of metric name with '_'
+func sanitize(name string) string {
+	out := []byte(name)
+	for i, c := range out {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			out[i] = '_'
+		}
+	}
+	return string(out)
+}
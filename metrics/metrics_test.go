@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	NewGaugeFunc("test_height", "height of chain", func() []Sample {
+		return []Sample{{[]string{"chain", "1"}, 100}, {[]string{"chain", "2"}, 5}}
+	})
+	h := NewHistogram("test_latency_seconds", "latency", []float64{0.1, 1}, "mode")
+	h.Observe(0.05, "run")
+	h.Observe(0.5, "run")
+	h.Observe(2, "run")
+	h.Observe(1, "error label number")
+
+	buf := new(bytes.Buffer)
+	WriteTo(buf)
+	out := buf.String()
+	lines := []string{
+		"# TYPE test_height gauge",
+		`test_height{chain="1"} 100`,
+		`test_height{chain="2"} 5`,
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{mode="run",le="0.1"} 1`,
+		`test_latency_seconds_bucket{mode="run",le="1"} 2`,
+		`test_latency_seconds_bucket{mode="run",le="+Inf"} 3`,
+		`test_latency_seconds_sum{mode="run"} 2.55`,
+		`test_latency_seconds_count{mode="run"} 3`,
+	}
+	for _, l := range lines {
+		if !strings.Contains(out, l+"\n") {
+			t.Error("not found:", l)
+		}
+	}
+}
@@ -2,19 +2,17 @@ package runtime
 
 import (
 	"bytes"
-	"context"
 	"encoding/binary"
 	"encoding/gob"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/conf"
+	"github.com/govm-net/govm/logger"
+	"github.com/govm-net/govm/metrics"
 	"github.com/govm-net/govm/wallet"
-	"github.com/lengzhao/database/client"
 	"io/ioutil"
-	"log"
 	"os"
-	"os/exec"
 	"path"
 	"reflect"
 	"strings"
@@ -37,10 +35,15 @@ var (
 	RunDir      = "."
 	BuildDir    = "."
 	NotRebuild  bool
+	runLog      = logger.New(logger.SubRuntime)
 )
 
 func init() {
 	loadEventFilter()
+	// the event_filter.json is reloaded with the configure
+	conf.OnReload(func(old, c conf.TConfig, changed []string) {
+		loadEventFilter()
+	})
 }
 
 // GetHash 计算hash值
@@ -53,7 +56,7 @@ func Encode(in interface{}) []byte {
 	buf := new(bytes.Buffer)
 	err := binary.Write(buf, binary.BigEndian, in)
 	if err != nil {
-		log.Println("fail to encode interface:", reflect.TypeOf(in).String(), in)
+		runLog.Error("fail to encode interface", "type", reflect.TypeOf(in).String(), "value", fmt.Sprint(in))
 		panic(err)
 		// return nil
 	}
@@ -65,8 +68,7 @@ func Decode(in []byte, out interface{}) int {
 	buf := bytes.NewReader(in)
 	err := binary.Read(buf, binary.BigEndian, out)
 	if err != nil {
-		log.Println("fail to decode interface:", in[:20], len(in))
-		log.Printf("type:%T\n", out)
+		runLog.Error("fail to decode interface", "type", fmt.Sprintf("%T", out), "length", len(in), logger.KeyError, err)
 		panic(err)
 		//return 0
 	}
@@ -77,7 +79,7 @@ func Decode(in []byte, out interface{}) int {
 func JSONEncode(in interface{}) []byte {
 	d, err := json.Marshal(in)
 	if err != nil {
-		log.Println("fail to encode interface:", reflect.TypeOf(in).String(), err)
+		runLog.Error("fail to encode interface", "type", reflect.TypeOf(in).String(), logger.KeyError, err)
 		panic(err)
 	}
 	return d
@@ -87,7 +89,7 @@ func JSONEncode(in interface{}) []byte {
 func JSONDecode(in []byte, out interface{}) int {
 	err := json.Unmarshal(in, out)
 	if err != nil {
-		log.Println("fail to decode interface:", reflect.TypeOf(out).String())
+		runLog.Error("fail to decode interface", "type", reflect.TypeOf(out).String(), logger.KeyError, err)
 		panic(err)
 	}
 	return 0
@@ -99,7 +101,7 @@ func GobEncode(in interface{}) []byte {
 	enc := gob.NewEncoder(&buf)
 	err := enc.Encode(in)
 	if err != nil {
-		log.Println("fail to encode interface:", reflect.TypeOf(in).String(), err)
+		runLog.Error("fail to encode interface", "type", reflect.TypeOf(in).String(), logger.KeyError, err)
 		panic(err)
 	}
 	return buf.Bytes()
@@ -111,7 +113,7 @@ func GobDecode(in []byte, out interface{}) int {
 	dec := gob.NewDecoder(buf)
 	err := dec.Decode(out)
 	if err != nil {
-		log.Println("fail to decode interface:", reflect.TypeOf(out).String())
+		runLog.Error("fail to decode interface", "type", reflect.TypeOf(out).String(), logger.KeyError, err)
 		panic(err)
 	}
 	return len(in) - buf.Len()
@@ -141,68 +143,59 @@ type TRunParam struct {
 	Cost      uint64
 	Energy    uint64
 	ErrorInfo string
+	Events    []TAppEvent
+	Used      uint64
+	Keys      []TTouchedKey
+	// TestData the db data written by the caller in test mode
+	TestData map[string][]byte
 }
 
-// RunApp run app
-func RunApp(client *client.Client, flag []byte, chain uint64, mode string, appName, user, data []byte, energy, cost uint64) {
-	args := TRunParam{chain, flag, user, data, cost, energy, ""}
-	var buf bytes.Buffer
-	var err error
-	enc := gob.NewEncoder(&buf)
-	enc.Encode(args)
-	var paramKey []byte
-	if client == nil {
-		client = database.GetClient()
-	}
-	if mode == "" {
-		paramKey = []byte(hexToPackageName(appName))
-	} else {
-		paramKey = []byte("test")
-	}
-	err = client.Set(chain, []byte("app_run"), paramKey, buf.Bytes())
-	if err != nil {
-		log.Println("[db]fail to write data.", err)
-		panic("retry")
-	}
+// RunApp run app in the worker process, return the result of the app,
+// panic *TRetryError if the worker fail, panic *TAppError if the app fail.
+// the events of test mode are not sent, they are returned to the caller
+func RunApp(flag []byte, chain uint64, mode string, appName, user, data []byte, energy, cost uint64) *TRunParam {
+	args := TRunParam{Chain: chain, Flag: flag, User: user, Data: data, Cost: cost, Energy: energy}
+	return runApp(mode, appName, args)
+}
+
+func runApp(mode string, appName []byte, args TRunParam) *TRunParam {
+	chain := args.Chain
+	flag := args.Flag
 	appPath := GetFullPathOfApp(chain, appName)
 	appPath = path.Join(AppPath, appPath, execName)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
-	defer cancel()
-
-	var cmd *exec.Cmd
-	if mode != "" {
-		cmd = exec.CommandContext(ctx, appPath, "-mode", mode)
-	} else {
-		cmd = exec.CommandContext(ctx, appPath)
-	}
-
-	cmd.Dir = RunDir
-	cmd.Stdout = log.Writer()
-	cmd.Stderr = log.Writer()
-	err = cmd.Run()
+	start := time.Now()
+	err := runInWorker(appPath, mode, &args)
+	appLatency.Observe(time.Since(start).Seconds(), modeName(mode), resultName(err))
 	if err != nil {
-		log.Println("fail to exec app.", err)
-		panic("retry")
+		runLog.Warn("fail to run app", logger.KeyChain, chain, logger.KeyApp, appName, "mode", modeName(mode), logger.KeyError, err)
+		panic(err)
+	}
+	if mode != "" && !collectEvents {
+		return &args
 	}
-	var d []byte
-	d = client.Get(chain, []byte("app_run"), paramKey)
-	if len(d) == 0 {
-		log.Println("[db]fail to get data.")
-		panic("retry")
+	for _, e := range args.Events {
+		emitEvent(e)
 	}
+	appKeys = append(appKeys, args.Keys...)
+	emitAppRun(chain, flag, appName, args.Used)
+	return &args
+}
 
-	rst := bytes.NewBuffer(d)
-	dec := gob.NewDecoder(rst)
-	err = dec.Decode(&args)
-	if err != nil {
-		log.Println("decode error:", err)
-		panic("retry")
+var appLatency = metrics.NewHistogram("govm_app_exec_seconds",
+	"latency of app execution", nil, "mode", "result")
+
+func modeName(mode string) string {
+	if mode == "" {
+		return "run"
 	}
+	return mode
+}
 
-	if args.ErrorInfo != "ok" {
-		panic(args.ErrorInfo)
+func resultName(err error) string {
+	if err != nil {
+		return "error"
 	}
+	return "ok"
 }
 
 func createDir(dirName string) {
@@ -216,16 +209,18 @@ func createDir(dirName string) {
 func loadEventFilter() {
 	data, err := ioutil.ReadFile("./conf/event_filter.json")
 	if err != nil {
-		log.Println("fail to read file,event_filter.json")
+		runLog.Debug("fail to read file", "file", "event_filter.json", logger.KeyError, err)
 		return
 	}
-	filter.mu.Lock()
-	defer filter.mu.Unlock()
-	err = json.Unmarshal(data, &filter.sw)
+	var sw map[string]string
+	err = json.Unmarshal(data, &sw)
 	if err != nil {
-		log.Println("fail to Unmarshal configure,event_filter.json")
+		runLog.Warn("fail to Unmarshal configure", "file", "event_filter.json", logger.KeyError, err)
 		return
 	}
+	filter.mu.Lock()
+	defer filter.mu.Unlock()
+	filter.sw = sw
 }
 
 // GetAppName 用app的私有结构体，获取app的Hash名字
@@ -0,0 +1,103 @@
+package runtime
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/govm-net/govm/event"
+	"github.com/govm-net/govm/messages"
+)
+
+// TAppEvent the event of app
+type TAppEvent struct {
+	Chain  uint64
+	Block  []byte
+	App    []byte
+	Struct string
+	Event  string
+	Params [][]byte
+}
+
+// collectEvents the app worker collect the events and return them to the node
+var collectEvents bool
+var appEvents []TAppEvent
+var childUsed uint64
+
+// TTouchedKey the key of db or log written in test mode
+type TTouchedKey struct {
+	App    []byte
+	Struct string
+	Key    []byte
+	IsLog  bool
+}
+
+var appKeys []TTouchedKey
+
+// callerData the db data written by the caller in test mode, the app worker read it before the database
+var callerData map[string][]byte
+
+// SetCallerData set the db data written by the caller in test mode, used by app worker
+func SetCallerData(data map[string][]byte) {
+	callerData = data
+}
+
+// splitOwner return the app name and the struct name of the owner
+func splitOwner(owner interface{}) ([]byte, string) {
+	var app []byte
+	tn := strings.TrimPrefix(fmt.Sprintf("%T", owner), "*")
+	items := strings.SplitN(tn, ".", 2)
+	if len(items) != 2 {
+		return nil, tn
+	}
+	app, _ = hex.DecodeString(items[0][1:])
+	return app, items[1]
+}
+
+func newAppEvent(chain uint64, flag []byte, user interface{}, name string, param [][]byte) TAppEvent {
+	out := TAppEvent{Chain: chain, Block: flag, Event: name, Params: param}
+	out.App, out.Struct = splitOwner(user)
+	return out
+}
+
+// emitEvent send the event to subscriber,
+// the app worker keep it and the node send it after the app finished
+func emitEvent(e TAppEvent) {
+	if collectEvents {
+		appEvents = append(appEvents, e)
+		return
+	}
+	event.Send(&messages.AppEvent{Chain: e.Chain, Block: e.Block, App: e.App,
+		Struct: e.Struct, Event: e.Event, Params: e.Params})
+}
+
+// TakeEvents return the events of the app call, used by app worker
+func TakeEvents() []TAppEvent {
+	out := appEvents
+	appEvents = nil
+	return out
+}
+
+// emitAppRun send the used energy of the app to the node,
+// the app worker add it to the used energy of itself
+func emitAppRun(chain uint64, flag, appName []byte, used uint64) {
+	if collectEvents {
+		childUsed += used
+		return
+	}
+	event.Send(&messages.AppRun{Chain: chain, Block: flag, App: appName, Used: used})
+}
+
+// TakeTouchedKeys return the keys written by the app call in test mode, used by app worker
+func TakeTouchedKeys() []TTouchedKey {
+	out := appKeys
+	appKeys = nil
+	return out
+}
+
+// TakeChildUsed return the used energy of the apps called by current app, used by app worker
+func TakeChildUsed() uint64 {
+	out := childUsed
+	childUsed = 0
+	return out
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/govm-net/govm/conf"
 	"github.com/govm-net/govm/counter"
+	"github.com/govm-net/govm/logger"
 )
 
 // TDependItem app的依赖信息
@@ -56,6 +57,9 @@ const (
 // var envItems = []string{"GO111MODULE=on"}
 var envItems = []string{}
 
+// buildOutput the output of go build, such as the compile error of app
+var buildOutput = logger.NewWriter(runLog.With("cmd", "go build"), logger.LevelWarn)
+
 const execName = "app.exe"
 const codeName = "app.go"
 
@@ -78,12 +82,12 @@ func NewApp(chain uint64, name []byte, code []byte) {
 		coreFile := path.Join(BuildDir, "./core/core.tmpl")
 		s1, err := template.ParseFiles(coreFile)
 		if err != nil {
-			log.Println("fail to ParseFiles core.tmpl:", err)
+			runLog.Error("fail to ParseFiles core.tmpl", logger.KeyError, err)
 			panic(err)
 		}
 		f, err := os.Create(dstFileName)
 		if err != nil {
-			log.Println("fail to create run file:", dstFileName, err)
+			runLog.Error("fail to create run file", "file", dstFileName, logger.KeyError, err)
 			panic(err)
 		}
 		defer f.Close()
@@ -91,7 +95,7 @@ func NewApp(chain uint64, name []byte, code []byte) {
 		info := TAppInfo{hexToPackageName(name), packPath, filePath, chain}
 		err = s1.Execute(f, info)
 		if err != nil {
-			log.Println("fail to execute run file:", dstFileName, err)
+			runLog.Error("fail to execute run file", "file", dstFileName, logger.KeyError, err)
 			f.Close()
 			panic(err)
 		}
@@ -169,7 +173,7 @@ func NewApp(chain uint64, name []byte, code []byte) {
 	//生成原始代码文件
 	f, err := os.Create(srcRelFN)
 	if err != nil {
-		log.Println("fail to create go file:", srcRelFN, err)
+		runLog.Error("fail to create go file", "file", srcRelFN, logger.KeyError, err)
 		panic(err)
 	}
 	createSourceFile(chain, appName, nInfo.Depends, code, f)
@@ -177,8 +181,8 @@ func NewApp(chain uint64, name []byte, code []byte) {
 	//编译、校验原始代码
 	cmd := exec.Command("go", "build", srcFilePath)
 	cmd.Dir = BuildDir
-	cmd.Stdout = log.Writer()
-	cmd.Stderr = log.Writer()
+	cmd.Stdout = buildOutput
+	cmd.Stderr = buildOutput
 	cmd.Env = os.Environ()
 	for _, item := range envItems {
 		cmd.Env = append(cmd.Env, item)
@@ -186,29 +190,29 @@ func NewApp(chain uint64, name []byte, code []byte) {
 
 	err = cmd.Run()
 	if err != nil {
-		log.Println("fail to build source file:", srcFilePath, err)
+		runLog.Warn("fail to build source file", logger.KeyChain, chain, logger.KeyApp, name, "file", srcFilePath, logger.KeyError, err)
 		panic(err)
 	}
 
 	//为原始代码添加代码统计，生成目标带统计的代码文件
 	lineNum := counter.Annotate(srcRelFN, dstRelFN)
 	if lineNum != uint64(nInfo.LineNum) {
-		log.Println("error line number:", lineNum, ",hope:", nInfo.LineNum)
+		runLog.Warn("error line number", logger.KeyChain, chain, logger.KeyApp, name, "line_number", lineNum, "hope", nInfo.LineNum)
 		panic(lineNum)
 	}
 
 	//再次编译，确认没有代码冲突
 	cmd = exec.Command("go", "build", dstFileName)
 	cmd.Dir = BuildDir
-	cmd.Stdout = log.Writer()
-	cmd.Stderr = log.Writer()
+	cmd.Stdout = buildOutput
+	cmd.Stderr = buildOutput
 	cmd.Env = os.Environ()
 	for _, item := range envItems {
 		cmd.Env = append(cmd.Env, item)
 	}
 	err = cmd.Run()
 	if err != nil {
-		log.Println("fail to build source file:", srcFilePath, err)
+		runLog.Warn("fail to build source file", logger.KeyChain, chain, logger.KeyApp, name, "file", dstFileName, logger.KeyError, err)
 		panic(err)
 	}
 
@@ -269,7 +273,7 @@ func makeAppExe(chain uint64, name []byte) {
 	info := TAppInfo{hexToPackageName(name), packPath, corePath, chain}
 	s1, err := template.ParseFiles(path.Join(BuildDir, "run.tmpl"))
 	if err != nil {
-		log.Println("fail to ParseFiles run.tmpl:", err)
+		runLog.Error("fail to ParseFiles run.tmpl", logger.KeyError, err)
 		panic(err)
 	}
 	realPath := GetFullPathOfApp(chain, name)
@@ -277,12 +281,12 @@ func makeAppExe(chain uint64, name []byte) {
 	defer os.Remove(runFile)
 	f, err := os.Create(runFile)
 	if err != nil {
-		log.Println("fail to create run file:", runFile, err)
+		runLog.Error("fail to create run file", "file", runFile, logger.KeyError, err)
 		panic(err)
 	}
 	err = s1.Execute(f, info)
 	if err != nil {
-		log.Println("fail to execute run file:", runFile, err)
+		runLog.Error("fail to execute run file", "file", runFile, logger.KeyError, err)
 		f.Close()
 		panic(err)
 	}
@@ -304,18 +308,19 @@ func makeAppExe(chain uint64, name []byte) {
 	//再次编译，确认没有代码冲突
 	cmd := exec.Command("go", "build", "-o", exeFile, fn)
 	cmd.Dir = BuildDir
-	cmd.Stdout = log.Writer()
-	cmd.Stderr = log.Writer()
+	cmd.Stdout = buildOutput
+	cmd.Stderr = buildOutput
 	cmd.Env = os.Environ()
 	for _, item := range envItems {
 		cmd.Env = append(cmd.Env, item)
 	}
 	err = cmd.Run()
 	if err != nil {
-		log.Println("fail to build source file:", exeFile, fn, err)
+		runLog.Error("fail to build app", logger.KeyChain, chain, logger.KeyApp, name, "file", fn, logger.KeyError, err)
 		panic(err)
 	}
 	binFile := path.Join(BuildDir, realPath, execName)
+	pool.close(path.Join(AppPath, realPath, execName))
 	os.Remove(binFile)
 	os.Rename(path.Join(BuildDir, exeFile), binFile)
 }
@@ -335,19 +340,19 @@ func RebuildApp(chain uint64, dir string) error {
 		dir := filepath.Dir(fPath)
 		name := filepath.Base(dir)
 		if name == "" {
-			log.Println("unknow path:", fPath)
+			runLog.Warn("unknow path", "path", fPath)
 			return nil
 		}
 		appName, err := hex.DecodeString(name[1:])
 		if err != nil {
-			log.Println("fail to decode:", name, err)
+			runLog.Warn("fail to decode app name", "path", fPath, logger.KeyError, err)
 			return nil
 		}
 		makeAppExe(chain, appName)
 		return nil
 	})
 	if err != nil {
-		log.Println("fail:", err)
+		runLog.Warn("fail to rebuild app", logger.KeyChain, chain, "dir", dir, logger.KeyError, err)
 	}
 	return err
 }
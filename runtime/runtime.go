@@ -5,9 +5,8 @@ import (
 	"fmt"
 	"github.com/govm-net/govm/counter"
 	db "github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/logger"
 	"github.com/govm-net/govm/wallet"
-	"github.com/lengzhao/database/client"
-	"log"
 	"reflect"
 	"strings"
 )
@@ -17,9 +16,18 @@ type TRuntime struct {
 	Chain    uint64
 	Flag     []byte
 	testMode bool
-	db       *client.Client
+	db       db.Client
 	dbData   map[string][]byte
 	logData  map[string][]byte
+	result   TTestResult
+	touched  map[string]bool
+}
+
+// TTestResult the result of test mode, the events, the energy used by apps and the keys written
+type TTestResult struct {
+	Events []TAppEvent
+	Used   uint64
+	Keys   []TTouchedKey
 }
 
 const (
@@ -37,7 +45,7 @@ func assert(cond bool) {
 func NewRuntime(addrType, address string) *TRuntime {
 	out := new(TRuntime)
 	if address != "" {
-		out.db = client.New(addrType, address, 1)
+		out.db = db.NewRPCClient(addrType, address, 1)
 	} else {
 		out.db = db.GetClient()
 	}
@@ -55,6 +63,44 @@ func (r *TRuntime) SetTestMode() {
 	r.testMode = true
 	r.dbData = make(map[string][]byte)
 	r.logData = make(map[string][]byte)
+	r.result = TTestResult{}
+	r.touched = make(map[string]bool)
+}
+
+// TestResult return the result of test mode
+func (r *TRuntime) TestResult() TTestResult {
+	return r.result
+}
+
+// SetTestData set the data of db in test mode, it is not a touched key
+func (r *TRuntime) SetTestData(owner interface{}, key, value []byte, life uint64) {
+	assert(r.testMode)
+	k := fmt.Sprintf("%s_%x", GetStructName(owner), key)
+	v := make([]byte, len(value), len(value)+8)
+	copy(v, value)
+	r.dbData[k] = append(v, r.Encode(0, life)...)
+}
+
+// touch record the key written in test mode
+func (r *TRuntime) touch(owner interface{}, key []byte, isLog bool) {
+	app, st := splitOwner(owner)
+	tk := TTouchedKey{app, st, key, isLog}
+	if collectEvents {
+		appKeys = append(appKeys, tk)
+		return
+	}
+	r.addTouched(tk)
+}
+
+func (r *TRuntime) addTouched(keys ...TTouchedKey) {
+	for _, it := range keys {
+		k := fmt.Sprintf("%x_%s_%x_%t", it.App, it.Struct, it.Key, it.IsLog)
+		if r.touched[k] {
+			continue
+		}
+		r.touched[k] = true
+		r.result.Keys = append(r.result.Keys, it)
+	}
 }
 
 // GetHash 计算hash值
@@ -167,6 +213,7 @@ func (r *TRuntime) DbSet(owner interface{}, key, value []byte, life uint64) {
 	if r.testMode {
 		k := fmt.Sprintf("%s_%x", tbName, key)
 		r.dbData[k] = value
+		r.touch(owner, key, false)
 		return
 	}
 	err := r.db.SetWithFlag(r.Chain, r.Flag, tbName, key, value)
@@ -184,6 +231,9 @@ func (r *TRuntime) DbGet(owner interface{}, key []byte) ([]byte, uint64) {
 	if r.testMode {
 		k := fmt.Sprintf("%s_%x", tbName, key)
 		data, ok = r.dbData[k]
+		if !ok {
+			data, ok = callerData[k]
+		}
 	}
 	if !ok {
 		data = r.db.Get(r.Chain, tbName, key)
@@ -214,6 +264,7 @@ func (r *TRuntime) LogWrite(owner interface{}, key, value []byte, life uint64) {
 	if r.testMode {
 		k := fmt.Sprintf("%s_%x", tbName, key)
 		r.logData[k] = value
+		r.touch(owner, key, true)
 		return
 	}
 	err := r.db.SetWithFlag(r.Chain, r.Flag, tbName, key, value)
@@ -390,25 +441,52 @@ func (r *TRuntime) NewApp(name []byte, code []byte) {
 // RunApp 执行app，返回执行的指令数量
 func (r *TRuntime) RunApp(name, user, data []byte, energy, cost uint64) {
 	// log.Println("run app:", "a"+hex.EncodeToString(name))
-	if r.testMode {
-		RunApp(r.db, r.Flag, r.Chain, "test", name, user, data, energy, cost)
-	} else {
-		RunApp(r.db, r.Flag, r.Chain, "", name, user, data, energy, cost)
+	if !r.testMode {
+		RunApp(r.Flag, r.Chain, "", name, user, data, energy, cost)
+		return
+	}
+	defer func() {
+		e := recover()
+		if ae, ok := e.(*TAppError); ok {
+			r.result.Used += ae.Used
+		}
+		if e != nil {
+			panic(e)
+		}
+	}()
+	td := make(map[string][]byte)
+	for k, v := range callerData {
+		td[k] = v
+	}
+	for k, v := range r.dbData {
+		td[k] = v
+	}
+	args := TRunParam{Chain: r.Chain, Flag: r.Flag, User: user, Data: data, Cost: cost, Energy: energy, TestData: td}
+	rst := runApp("test", name, args)
+	if !collectEvents {
+		r.result.Events = append(r.result.Events, rst.Events...)
+		r.result.Used += rst.Used
+		r.addTouched(rst.Keys...)
 	}
 }
 
 // Event event
 func (r *TRuntime) Event(user interface{}, event string, param ...[]byte) {
 	pn := fmt.Sprintf("%T.%s", user, event)
+	if !r.testMode || collectEvents {
+		emitEvent(newAppEvent(r.Chain, r.Flag, user, event, param))
+	} else {
+		r.result.Events = append(r.result.Events, newAppEvent(r.Chain, r.Flag, user, event, param))
+	}
 	filter.mu.Lock()
 	defer filter.mu.Unlock()
 	if filter.sw == nil {
-		log.Printf("event:%d,%s,%x\n", r.Chain, pn, param)
+		runLog.Debug("event", logger.KeyChain, r.Chain, "event", pn, "params", param)
 		return
 	}
 	alias := filter.sw[pn]
 	if alias != "" {
-		log.Printf("event:%d,%s,%x\n", r.Chain, alias, param)
+		runLog.Info("event", logger.KeyChain, r.Chain, "event", alias, "params", param)
 	}
 }
 
@@ -0,0 +1,64 @@
+package runtime
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+type tTestOwner struct{}
+
+func TestTestResult(t *testing.T) {
+	r := NewRuntime("", "")
+	r.SetInfo(1, []byte("testmode"))
+	r.SetTestMode()
+	r.SetTestData(tTestOwner{}, []byte("k0"), []byte("v0"), 100)
+	r.DbSet(tTestOwner{}, []byte("k1"), []byte("v1"), 100)
+	r.DbSet(tTestOwner{}, []byte("k1"), []byte("v2"), 100)
+	r.Event(tTestOwner{}, "test", []byte("param"))
+
+	v, life := r.DbGet(tTestOwner{}, []byte("k0"))
+	if string(v) != "v0" || life != 100 {
+		t.Errorf("error test data:%s,%d", v, life)
+	}
+	rst := r.TestResult()
+	if len(rst.Keys) != 1 || string(rst.Keys[0].Key) != "k1" || rst.Keys[0].Struct != "tTestOwner" {
+		t.Errorf("error touched keys:%v", rst.Keys)
+	}
+	if len(rst.Events) != 1 || rst.Events[0].Event != "test" {
+		t.Errorf("error events:%v", rst.Events)
+	}
+}
+
+// TestRunAppReadCaller the child app can read the data written by the caller in test mode
+func TestRunAppReadCaller(t *testing.T) {
+	os.Setenv("GOVM_FAKE_WORKER", "1")
+	defer os.Unsetenv("GOVM_FAKE_WORKER")
+	defer CloseWorkers()
+	dir, err := ioutil.TempDir("", "govm_app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	old := AppPath
+	AppPath = dir
+	defer func() { AppPath = old }()
+	name := []byte("child")
+	appPath := path.Join(AppPath, GetFullPathOfApp(1, name), execName)
+	os.MkdirAll(path.Dir(appPath), 0755)
+	exe, _ := os.Executable()
+	if err = os.Symlink(exe, appPath); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRuntime("", "")
+	r.SetInfo(1, []byte("testmode"))
+	r.SetTestMode()
+	r.DbSet(tTestOwner{}, []byte("k1"), []byte("v1"), 100)
+	r.RunApp(name, []byte("read_caller"), nil, 10000, 0)
+	rst := r.TestResult()
+	if len(rst.Events) != 1 || len(rst.Events[0].Params) != 1 || string(rst.Events[0].Params[0]) != "v1" {
+		t.Errorf("error events:%v", rst.Events)
+	}
+}
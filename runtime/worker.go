@@ -0,0 +1,253 @@
+package runtime
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/govm-net/govm/database"
+	"github.com/govm-net/govm/logger"
+)
+
+// TRetryError the failure of infrastructure(database,app process),
+// it is not the error of transaction, the caller can retry later
+type TRetryError struct {
+	Op  string
+	Err error
+}
+
+func (e *TRetryError) Error() string {
+	return fmt.Sprintf("retry,%s:%v", e.Op, e.Err)
+}
+
+// RetryInfo the prefix of ErrorInfo returned by the worker,
+// the worker fail to run the app because of infrastructure(such as broken parament), it is not the error of app
+const RetryInfo = "retry:"
+
+// TAppError the error returned by app, the transaction is invalid
+type TAppError struct {
+	Info string
+	Used uint64
+}
+
+func (e *TAppError) Error() string {
+	return e.Info
+}
+
+// IsRetryError return true if the error(or the value of recover) is TRetryError
+func IsRetryError(e interface{}) bool {
+	_, ok := e.(*TRetryError)
+	return ok
+}
+
+var (
+	// AppCallTimeout the deadline of every app call
+	AppCallTimeout = 100 * time.Second
+	// MaxIdleWorkers the max number of started workers(not used) per app
+	MaxIdleWorkers = 2
+)
+
+// appWorker the app process, it read TRunParam from stdin and write the result to stdout.
+// every worker runs only one call, so the package-level variables of app are not kept between calls
+type appWorker struct {
+	cmd    *exec.Cmd
+	in     io.WriteCloser
+	enc    *gob.Encoder
+	dec    *gob.Decoder
+	exited chan struct{}
+}
+
+// workerPool the workers started before the calls, it hides the time of starting process
+type workerPool struct {
+	mu       sync.Mutex
+	idle     map[string][]*appWorker
+	starting map[string]int
+	// gen is changed by close, the workers started before it are dropped
+	gen uint64
+}
+
+var pool = workerPool{idle: make(map[string][]*appWorker), starting: make(map[string]int)}
+
+func startWorker(appPath, mode string) (*appWorker, error) {
+	args := []string{"-worker"}
+	if mode != "" {
+		args = append(args, "-mode", mode)
+	}
+	cmd := exec.Command(appPath, args...)
+	cmd.Dir = RunDir
+	cmd.Env = append(os.Environ(), db.EnvAppProcess+"=1")
+	cmd.Stderr = logger.NewWriter(runLog.With(logger.KeyApp, appPath), logger.LevelInfo)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+	w := &appWorker{cmd: cmd, in: in, exited: make(chan struct{})}
+	w.enc = gob.NewEncoder(in)
+	w.dec = gob.NewDecoder(out)
+	go func() {
+		cmd.Wait()
+		close(w.exited)
+	}()
+	return w, nil
+}
+
+func (w *appWorker) alive() bool {
+	select {
+	case <-w.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+func (w *appWorker) kill() {
+	w.in.Close()
+	if w.alive() {
+		w.cmd.Process.Kill()
+	}
+}
+
+// call send the param to worker and wait the result before deadline
+func (w *appWorker) call(args *TRunParam, deadline time.Duration) error {
+	err := w.enc.Encode(args)
+	if err != nil {
+		return &TRetryError{"write param", err}
+	}
+	rst := make(chan error, 1)
+	go func() {
+		rst <- w.dec.Decode(args)
+	}()
+	timer := time.NewTimer(deadline)
+	defer timer.Stop()
+	select {
+	case err = <-rst:
+		if err != nil {
+			return &TRetryError{"read result", err}
+		}
+	case <-timer.C:
+		return &TRetryError{"timeout", fmt.Errorf("over %s", deadline)}
+	}
+	return nil
+}
+
+// get take a started worker of the app, and start another one for the next call
+func (p *workerPool) get(appPath, mode string) (*appWorker, error) {
+	key := appPath + mode
+	var w *appWorker
+	p.mu.Lock()
+	for len(p.idle[key]) > 0 && w == nil {
+		list := p.idle[key]
+		w = list[len(list)-1]
+		p.idle[key] = list[:len(list)-1]
+		if !w.alive() {
+			w = nil
+		}
+	}
+	p.mu.Unlock()
+	go p.prestart(appPath, mode)
+	if w != nil {
+		return w, nil
+	}
+	return startWorker(appPath, mode)
+}
+
+// prestart start a worker for the next call of the app
+func (p *workerPool) prestart(appPath, mode string) {
+	key := appPath + mode
+	p.mu.Lock()
+	if len(p.idle[key])+p.starting[key] >= MaxIdleWorkers {
+		p.mu.Unlock()
+		return
+	}
+	p.starting[key]++
+	gen := p.gen
+	p.mu.Unlock()
+
+	w, err := startWorker(appPath, mode)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.starting[key]--
+	if err != nil {
+		return
+	}
+	if gen != p.gen {
+		w.kill()
+		return
+	}
+	p.idle[key] = append(p.idle[key], w)
+}
+
+// close kill all idle workers of the app, if appPath is empty, kill all workers
+func (p *workerPool) close(appPath string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.gen++
+	for key, list := range p.idle {
+		if appPath != "" && key != appPath && key != appPath+"test" {
+			continue
+		}
+		for _, w := range list {
+			w.kill()
+		}
+		delete(p.idle, key)
+	}
+}
+
+// CloseWorkers kill all app workers
+func CloseWorkers() {
+	pool.close("")
+}
+
+// runInWorker run app in a new worker, the worker is killed after the call,
+// so every call starts with a clean process and the result is the same on all nodes
+func runInWorker(appPath, mode string, args *TRunParam) error {
+	w, err := pool.get(appPath, mode)
+	if err != nil {
+		return &TRetryError{"start worker", err}
+	}
+	defer w.kill()
+	err = w.call(args, AppCallTimeout)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(args.ErrorInfo, RetryInfo) {
+		return &TRetryError{"run app", errors.New(args.ErrorInfo[len(RetryInfo):])}
+	}
+	if args.ErrorInfo != "ok" {
+		return &TAppError{args.ErrorInfo, args.Used}
+	}
+	return nil
+}
+
+var resetHooks []func()
+
+// RegisterResetHook register the hook, it is called by the app worker before every call
+func RegisterResetHook(f func()) {
+	resetHooks = append(resetHooks, f)
+}
+
+// ResetApp run the reset hooks, clean the status of last call
+func ResetApp() {
+	collectEvents = true
+	appEvents = nil
+	appKeys = nil
+	callerData = nil
+	childUsed = 0
+	for _, f := range resetHooks {
+		f()
+	}
+}
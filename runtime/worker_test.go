@@ -0,0 +1,113 @@
+package runtime
+
+import (
+	"encoding/gob"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeCounter the package-level variable of the fake app
+var fakeCounter int
+
+// TestMain the test binary is also the fake app worker
+func TestMain(m *testing.M) {
+	if os.Getenv("GOVM_FAKE_WORKER") == "1" {
+		enc := gob.NewEncoder(os.Stdout)
+		dec := gob.NewDecoder(os.Stdin)
+		for {
+			args := TRunParam{}
+			if dec.Decode(&args) != nil {
+				os.Exit(0)
+			}
+			switch string(args.User) {
+			case "panic":
+				args.ErrorInfo = "error message:panic"
+			case "sleep":
+				time.Sleep(time.Second)
+				args.ErrorInfo = "ok"
+			case "exit":
+				os.Exit(1)
+			case "counter":
+				fakeCounter++
+				args.Data = []byte(strconv.Itoa(fakeCounter))
+				args.ErrorInfo = "ok"
+			case "":
+				args.ErrorInfo = RetryInfo + "empty user"
+			case "read_caller":
+				// the child app read the data written by the caller
+				SetCallerData(args.TestData)
+				r := NewRuntime("", "")
+				r.SetInfo(args.Chain, args.Flag)
+				r.SetTestMode()
+				v, _ := r.DbGet(tTestOwner{}, []byte("k1"))
+				args.Events = []TAppEvent{{Chain: args.Chain, Event: "read", Params: [][]byte{v}}}
+				args.TestData = nil
+				args.ErrorInfo = "ok"
+			default:
+				args.Data = append(args.Data, args.User...)
+				args.ErrorInfo = "ok"
+			}
+			enc.Encode(args)
+		}
+	}
+	os.Exit(m.Run())
+}
+
+func TestRunInWorker(t *testing.T) {
+	os.Setenv("GOVM_FAKE_WORKER", "1")
+	defer os.Unsetenv("GOVM_FAKE_WORKER")
+	defer CloseWorkers()
+	appPath, _ := os.Executable()
+
+	for i := 0; i < 3; i++ {
+		args := TRunParam{Chain: 1, User: []byte("user"), Data: []byte("data")}
+		err := runInWorker(appPath, "", &args)
+		if err != nil {
+			t.Fatal("fail to run app.", err)
+		}
+		if string(args.Data) != "datauser" {
+			t.Error("error result:", string(args.Data))
+		}
+	}
+
+	// the package-level variables of app are not kept between calls
+	for i := 0; i < 2; i++ {
+		args := TRunParam{Chain: 1, User: []byte("counter")}
+		err := runInWorker(appPath, "", &args)
+		if err != nil {
+			t.Fatal("fail to run app.", err)
+		}
+		if string(args.Data) != "1" {
+			t.Error("the worker is reused,counter:", string(args.Data))
+		}
+	}
+
+	args := TRunParam{Chain: 1, User: []byte("panic")}
+	err := runInWorker(appPath, "", &args)
+	if _, ok := err.(*TAppError); !ok {
+		t.Error("hope app error,get:", err)
+	}
+
+	args = TRunParam{Chain: 1}
+	err = runInWorker(appPath, "", &args)
+	if !IsRetryError(err) {
+		t.Error("hope retry error of empty user,get:", err)
+	}
+
+	args = TRunParam{Chain: 1, User: []byte("exit")}
+	err = runInWorker(appPath, "", &args)
+	if !IsRetryError(err) {
+		t.Error("hope retry error,get:", err)
+	}
+
+	old := AppCallTimeout
+	AppCallTimeout = 100 * time.Millisecond
+	defer func() { AppCallTimeout = old }()
+	args = TRunParam{Chain: 1, User: []byte("sleep")}
+	err = runInWorker(appPath, "", &args)
+	if !IsRetryError(err) {
+		t.Error("hope timeout,get:", err)
+	}
+}
@@ -0,0 +1,43 @@
+package testnet
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock the controllable clock of test network, the time is millisecond(same as core time).
+// use it by handler.SetClock(clock.Now)
+type Clock struct {
+	mu  sync.Mutex
+	now uint64
+}
+
+// NewClock new clock, start with the time(ms), use the system time if start is 0
+func NewClock(start uint64) *Clock {
+	if start == 0 {
+		start = uint64(time.Now().Unix()) * 1000
+	}
+	return &Clock{now: start}
+}
+
+// Now get the time(ms)
+func (c *Clock) Now() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set set the time(ms)
+func (c *Clock) Set(t uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance move the clock forward
+func (c *Clock) Advance(d time.Duration) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now += uint64(d / time.Millisecond)
+	return c.now
+}
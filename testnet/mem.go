@@ -0,0 +1,182 @@
+package testnet
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/lengzhao/libp2p"
+)
+
+// MemScheme the scheme of in-memory transport, address such as: mem://user@node1:1
+const MemScheme = "mem"
+
+// MemPool in-memory connection pool(libp2p.ConnPool), the connections are net.Pipe.
+// all nodes of one test network share the same MemPool
+type MemPool struct {
+	mu        sync.Mutex
+	listeners map[string]*memListener
+	waits     map[string]chan struct{}
+}
+
+type memListener struct {
+	handle func(libp2p.Conn)
+	closed chan struct{}
+}
+
+// NewMemPool new in-memory connection pool
+func NewMemPool() *MemPool {
+	out := new(MemPool)
+	out.listeners = make(map[string]*memListener)
+	out.waits = make(map[string]chan struct{})
+	return out
+}
+
+func (p *MemPool) waitChan(host string) chan struct{} {
+	c, ok := p.waits[host]
+	if !ok {
+		c = make(chan struct{})
+		p.waits[host] = c
+	}
+	return c
+}
+
+// Listen listen the host of address, it blocks until the pool is closed
+func (p *MemPool) Listen(address string, handle func(libp2p.Conn)) error {
+	u, err := url.Parse(address)
+	if err != nil {
+		return err
+	}
+	l := &memListener{handle: handle, closed: make(chan struct{})}
+	p.mu.Lock()
+	if _, ok := p.listeners[u.Host]; ok {
+		p.mu.Unlock()
+		return errors.New("the address is in use")
+	}
+	p.listeners[u.Host] = l
+	close(p.waitChan(u.Host))
+	p.mu.Unlock()
+	<-l.closed
+	return nil
+}
+
+// WaitListen wait until the host is listened
+func (p *MemPool) WaitListen(host string) {
+	p.mu.Lock()
+	c := p.waitChan(host)
+	p.mu.Unlock()
+	<-c
+}
+
+// Dial connect the listener of address
+func (p *MemPool) Dial(address string) (libp2p.Conn, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	l, ok := p.listeners[u.Host]
+	p.mu.Unlock()
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	c1, c2 := net.Pipe()
+	client := &memConn{Conn: c1}
+	client.peer = &memAddr{u: *u, server: true}
+	client.self = &memAddr{u: url.URL{Scheme: MemScheme, Host: "client"}}
+	server := &memConn{Conn: c2}
+	server.peer = &memAddr{u: url.URL{Scheme: MemScheme, Host: "client"}}
+	server.self = &memAddr{u: url.URL{Scheme: MemScheme, Host: u.Host}, server: true}
+	go l.handle(server)
+	return client, nil
+}
+
+// Close close all listeners
+func (p *MemPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k, l := range p.listeners {
+		close(l.closed)
+		delete(p.listeners, k)
+	}
+}
+
+// CloseHost close the listener of the host
+func (p *MemPool) CloseHost(host string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if l, ok := p.listeners[host]; ok {
+		close(l.closed)
+		delete(p.listeners, host)
+	}
+}
+
+// hostPool the connection pool of one node, Close only close the listener of the node
+type hostPool struct {
+	*MemPool
+	host string
+}
+
+func (p *hostPool) Close() {
+	p.CloseHost(p.host)
+}
+
+type memConn struct {
+	net.Conn
+	peer *memAddr
+	self *memAddr
+}
+
+func (c *memConn) RemoteAddr() libp2p.Addr {
+	return c.peer
+}
+
+func (c *memConn) LocalAddr() libp2p.Addr {
+	return c.self
+}
+
+type memAddr struct {
+	mu     sync.Mutex
+	u      url.URL
+	server bool
+}
+
+func (a *memAddr) String() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.u.String()
+}
+
+func (a *memAddr) Scheme() string {
+	return a.u.Scheme
+}
+
+func (a *memAddr) User() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.u.User == nil {
+		return ""
+	}
+	return a.u.User.Username()
+}
+
+func (a *memAddr) Host() string {
+	return a.u.Host
+}
+
+func (a *memAddr) IsServer() bool {
+	return a.server
+}
+
+func (a *memAddr) UpdateUser(user string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.u.User == nil || a.u.User.Username() == "" {
+		a.u.User = url.User(user)
+	}
+}
+
+func (a *memAddr) SetServer() {
+	a.server = true
+}
@@ -0,0 +1,204 @@
+// Package testnet the deterministic in-memory test network.
+// the nodes are libp2p network managers connected by the in-memory transport(MemPool),
+// the core time is controlled by Clock.
+//
+// the handler/core/database packages keep the state of node in package variables,
+// so only one node of the process can run the handler plugins, other nodes are scripted peers
+// which record the received messages and send the messages(blocks/transactions) of test.
+// it is not a network of full nodes, the fork choice, rollback(dbRollBack/autoRollback) and
+// cross-chain sync between full nodes are not covered until the state of node is moved out of
+// the package variables. the tests of package cover the transport and the clock only:
+//
+//	tn := testnet.New(0)
+//	defer tn.Close()
+//	handler.SetClock(tn.Clock.Now)
+//	node, _ := tn.AddNode("node", new(handler.MsgPlugin), new(handler.InternalPlugin), new(handler.SyncPlugin))
+//	peer, _ := tn.AddNode("peer1")
+//	tn.Connect(peer, node)
+//	peer.Send(node, &messages.BlockInfo{...})
+//	msg, _ := peer.Wait(time.Second, func(m interface{}) bool { _, ok := m.(*messages.ReqBlock); return ok })
+package testnet
+
+import (
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lengzhao/libp2p"
+	"github.com/lengzhao/libp2p/conn"
+	"github.com/lengzhao/libp2p/crypto"
+	"github.com/lengzhao/libp2p/network"
+)
+
+// Hello the first message of connection, the listener know the peer by it
+type Hello struct {
+	Name string
+}
+
+func init() {
+	gob.Register(&Hello{})
+}
+
+// Network the test network
+type Network struct {
+	mu    sync.Mutex
+	Clock *Clock
+	pool  *MemPool
+	nodes map[string]*Node
+}
+
+// Node the node of test network
+type Node struct {
+	Name    string
+	ID      string
+	Address string
+	Net     *network.Manager
+	rec     *recorder
+	mu      sync.Mutex
+	peers   map[string]libp2p.Session
+}
+
+// New new test network, the clock start with the time(ms), use the system time if start is 0
+func New(start uint64) *Network {
+	out := new(Network)
+	out.Clock = NewClock(start)
+	out.pool = NewMemPool()
+	out.nodes = make(map[string]*Node)
+	return out
+}
+
+// AddNode start a node with the plugins, the messages received by node are recorded
+func (n *Network) AddNode(name string, plugins ...libp2p.IPlugin) (*Node, error) {
+	n.mu.Lock()
+	if _, ok := n.nodes[name]; ok {
+		n.mu.Unlock()
+		return nil, fmt.Errorf("the node is exist:%s", name)
+	}
+	host := fmt.Sprintf("%s:%d", name, len(n.nodes)+1)
+	n.mu.Unlock()
+	node := &Node{Name: name, rec: newRecorder(), peers: make(map[string]libp2p.Session)}
+	node.Net = network.New()
+	pm := conn.NewMgr()
+	pm.RegConnPool(MemScheme, &hostPool{n.pool, host})
+	node.Net.SetConnPoolMgr(pm)
+	km := crypto.GetDefaultMgr()
+	node.ID = hex.EncodeToString(km.GetPublic())
+	node.Net.SetKeyMgr(km)
+	node.Net.RegistPlugin(node.rec)
+	for _, p := range plugins {
+		node.Net.RegistPlugin(p)
+	}
+	go node.Net.Listen(MemScheme + "://" + host)
+	n.pool.WaitListen(host)
+	node.Address = node.Net.GetAddress()
+	n.mu.Lock()
+	n.nodes[name] = node
+	n.mu.Unlock()
+	return node, nil
+}
+
+// Node get the node by name
+func (n *Network) Node(name string) *Node {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.nodes[name]
+}
+
+// Connect connect the node to peer
+func (n *Network) Connect(node, peer *Node) error {
+	s, err := node.Net.NewSession(peer.Address)
+	if err != nil {
+		return err
+	}
+	node.mu.Lock()
+	node.peers[peer.Name] = s
+	node.mu.Unlock()
+	return s.Send(&Hello{node.Name})
+}
+
+// Close close all nodes
+func (n *Network) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, node := range n.nodes {
+		node.Net.Close()
+	}
+	n.pool.Close()
+}
+
+// Send send the message to the connected peer
+func (node *Node) Send(peer *Node, msg interface{}) error {
+	node.mu.Lock()
+	s, ok := node.peers[peer.Name]
+	node.mu.Unlock()
+	if !ok {
+		// the peer connected the node
+		s = node.rec.session(peer.ID)
+	}
+	if s == nil {
+		return errors.New("not connected")
+	}
+	return s.Send(msg)
+}
+
+// Received get the messages received by the node
+func (node *Node) Received() []interface{} {
+	return node.rec.list()
+}
+
+// Wait wait the message which match the filter, return error if timeout.
+// the timeout is the real time, it is independent of Clock
+func (node *Node) Wait(timeout time.Duration, match func(msg interface{}) bool) (interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	var offset int
+	for {
+		msgs := node.rec.list()
+		for ; offset < len(msgs); offset++ {
+			if match(msgs[offset]) {
+				return msgs[offset], nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// recorder the plugin which record the received messages and the sessions
+type recorder struct {
+	*libp2p.Plugin
+	mu       sync.Mutex
+	msgs     []interface{}
+	sessions map[string]libp2p.Session
+}
+
+func newRecorder() *recorder {
+	return &recorder{Plugin: new(libp2p.Plugin), sessions: make(map[string]libp2p.Session)}
+}
+
+func (r *recorder) Receive(e libp2p.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, e.GetMessage())
+	r.sessions[hex.EncodeToString(e.GetPeerID())] = e.GetSession()
+	return nil
+}
+
+func (r *recorder) list() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]interface{}, len(r.msgs))
+	copy(out, r.msgs)
+	return out
+}
+
+// session get the session which is connected by the peer
+func (r *recorder) session(id string) libp2p.Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessions[id]
+}
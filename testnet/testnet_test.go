@@ -0,0 +1,62 @@
+package testnet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/govm-net/govm/messages"
+)
+
+func TestNetwork(t *testing.T) {
+	tn := New(1000)
+	defer tn.Close()
+	n1, err := tn.AddNode("n1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n2, err := tn.AddNode("n2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn.Connect(n1, n2); err != nil {
+		t.Fatal(err)
+	}
+	err = n1.Send(n2, &messages.ReqBlockInfo{Chain: 1, Index: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := n2.Wait(time.Second, func(m interface{}) bool {
+		_, ok := m.(*messages.ReqBlockInfo)
+		return ok
+	})
+	if err != nil || msg.(*messages.ReqBlockInfo).Index != 10 {
+		t.Fatal("fail to receive message:", msg, err)
+	}
+	// reply by the session of n1
+	err = n2.Send(n1, &messages.BlockInfo{Chain: 1, Index: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = n1.Wait(time.Second, func(m interface{}) bool {
+		_, ok := m.(*messages.BlockInfo)
+		return ok
+	})
+	if err != nil {
+		t.Error("fail to receive reply:", err)
+	}
+	if _, err = n1.Wait(10*time.Millisecond, func(m interface{}) bool { return false }); err == nil {
+		t.Error("hope timeout")
+	}
+}
+
+func TestClock(t *testing.T) {
+	c := NewClock(1000)
+	c.Advance(2 * time.Second)
+	if c.Now() != 3000 {
+		t.Error("error time:", c.Now())
+	}
+	c.Set(10)
+	if c.Now() != 10 {
+		t.Error("error time:", c.Now())
+	}
+}
@@ -0,0 +1,312 @@
+// Command govm-tx build and sign transaction offline, output the raw data of transaction.
+// the data can be submitted to node by POST /api/v1/{chain}/transaction/raw (raw bytes or hex string)
+// or the JSON-RPC govm_sendRawTransaction(data is hex string).
+//
+//	govm-tx -chain 1 -ops transfer -peer 01ccaf... -cost 1000000000 > trans.hex
+//	govm-tx -chain 1 -ops run_app -app 1234... -param 0102 -cost 100 -format raw -o trans.dat
+//
+// the energy of run_app can be estimated by the node, it runs the app call in test mode:
+//
+//	govm-tx -chain 1 -ops run_app -app 1234... -param 0102 -node http://127.0.0.1:9090 -estimate
+//	govm-tx -chain 1 -ops run_app -app 1234... -param 0102 -node http://127.0.0.1:9090 -auto_energy > trans.hex
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	core "github.com/govm-net/govm/core"
+	"github.com/govm-net/govm/runtime"
+	"github.com/govm-net/govm/wallet"
+)
+
+var (
+	walletFile = flag.String("wallet", "./conf/wallet.key", "wallet file")
+	password   = flag.String("password", "", "password of wallet, default $GOVM_PASSWORD")
+	keyStore   = flag.String("keystore", "./conf/keystore.json", "keystore file, used with -from")
+	from       = flag.String("from", "", "the account(name or address) of keystore, use the wallet file if empty")
+	chain      = flag.Uint64("chain", 1, "chain of transaction")
+	ops        = flag.String("ops", "", "ops of transaction:transfer,move,new_chain,new_app,run_app,app_life,miner,admin,vote,unvote")
+	cost       = flag.Uint64("cost", 0, "cost of transaction(t9)")
+	energy     = flag.Uint64("energy", 0, "energy of transaction, use default value if less than it")
+	transTime  = flag.Uint64("time", 0, "time of transaction(ms), default now")
+	peer       = flag.String("peer", "", "address of payee(transfer)/admin(vote)/miner(miner)/voter(unvote), hex string")
+	dstChain   = flag.Uint64("dst_chain", 0, "destination chain(move,new_chain,miner)")
+	appName    = flag.String("app", "", "app name(run_app,app_life), hex string")
+	param      = flag.String("param", "", "param of run_app, hex string")
+	code       = flag.String("code", "", "source code file of new_app")
+	private    = flag.Bool("private", false, "new_app: private app")
+	enableRun  = flag.Bool("run", true, "new_app: enable run")
+	enableImp  = flag.Bool("import", false, "new_app: enable import")
+	life       = flag.Uint64("life", 0, "app_life: life(ms)")
+	format     = flag.String("format", "hex", "output format:hex,raw")
+	output     = flag.String("o", "", "output file, default stdout")
+	node       = flag.String("node", "", "address of node, used to estimate the energy of run_app, such as http://127.0.0.1:9090")
+	estimate   = flag.Bool("estimate", false, "run_app: print the energy estimated by the node(-node), not output the transaction")
+	autoEnergy = flag.Bool("auto_energy", false, "run_app: use the energy estimated by the node(-node) with margin")
+	margin     = flag.Uint64("margin", 20, "the safety margin(percent) of the estimated energy")
+	apiKey     = flag.String("api_key", "", "api key of node(-node), default $GOVM_API_KEY")
+)
+
+func decodeHex(name, in string, length int) ([]byte, error) {
+	out, err := hex.DecodeString(in)
+	if err != nil {
+		return nil, fmt.Errorf("error %s,must hex string:%s", name, err)
+	}
+	if length > 0 && len(out) != length {
+		return nil, fmt.Errorf("error length of %s,hope:%d,get:%d", name, length, len(out))
+	}
+	return out, nil
+}
+
+func buildTransaction(user core.Address) (*core.StTrans, error) {
+	trans := core.NewTransaction(*chain, user)
+	if *transTime > 0 {
+		trans.Time = *transTime
+	}
+	switch *ops {
+	case "transfer":
+		if *cost == 0 {
+			return nil, errors.New("error cost value")
+		}
+		d, err := decodeHex("peer", *peer, core.AddressLen)
+		if err != nil {
+			return nil, err
+		}
+		payee := core.Address{}
+		runtime.Decode(d, &payee)
+		trans.CreateTransfer(payee, *cost)
+	case "move":
+		trans.CreateMove(*dstChain, *cost)
+	case "new_chain":
+		trans.CreateNewChain(*dstChain, *cost)
+	case "new_app":
+		if *code == "" {
+			return nil, errors.New("need source code file")
+		}
+		var flag uint8
+		if !*private {
+			flag |= core.AppFlagPlublc
+		}
+		if *enableRun {
+			flag |= core.AppFlagRun
+		}
+		if *enableImp {
+			flag |= core.AppFlagImport
+		}
+		c, ln := core.CreateAppFromSourceCode(*code, flag)
+		trans.CreateNewApp(c, ln)
+	case "run_app":
+		d, err := decodeHex("app", *appName, core.HashLen)
+		if err != nil {
+			return nil, err
+		}
+		var p []byte
+		if *param != "" {
+			p, err = decodeHex("param", *param, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		app := core.Hash{}
+		runtime.Decode(d, &app)
+		trans.CreateRunApp(app, *cost, p)
+	case "app_life":
+		d, err := decodeHex("app", *appName, core.HashLen)
+		if err != nil {
+			return nil, err
+		}
+		app := core.Hash{}
+		runtime.Decode(d, &app)
+		trans.CreateUpdateAppLife(app, *life)
+	case "miner":
+		var p []byte
+		if *peer != "" {
+			var err error
+			p, err = decodeHex("peer", *peer, core.AddressLen)
+			if err != nil {
+				return nil, err
+			}
+		}
+		trans.RegisterMiner(*dstChain, *cost, p)
+	case "admin":
+		trans.RegisterAdmin(*cost)
+	case "vote":
+		d, err := decodeHex("peer", *peer, core.AddressLen)
+		if err != nil {
+			return nil, err
+		}
+		trans.VoteAdmin(*cost, d)
+	case "unvote":
+		var d []byte
+		if *peer != "" {
+			var err error
+			d, err = decodeHex("peer", *peer, core.AddressLen)
+			if err != nil {
+				return nil, err
+			}
+		}
+		trans.Unvote(d)
+	default:
+		return nil, fmt.Errorf("unknown ops:%s", *ops)
+	}
+	if *energy > trans.Energy {
+		trans.Energy = *energy
+	}
+	return trans, nil
+}
+
+// energyEstimate the response of POST /api/v1/{chain}/transaction/app/energy
+type energyEstimate struct {
+	Used      uint64 `json:"energy_used"`
+	MinEnergy uint64 `json:"min_energy"`
+	Energy    uint64 `json:"energy"`
+	Margin    uint64 `json:"margin"`
+	Error     string `json:"error,omitempty"`
+}
+
+// estimateEnergy estimate the energy of run_app by the node
+func estimateEnergy(user core.Address) (energyEstimate, error) {
+	var out energyEstimate
+	if *node == "" {
+		return out, errors.New("need the address of node(-node)")
+	}
+	if *ops != "run_app" {
+		return out, errors.New("only support run_app")
+	}
+	info := map[string]interface{}{
+		"user":     hex.EncodeToString(user[:]),
+		"app_name": *appName,
+		"param":    *param,
+		"cost":     *cost,
+		"margin":   *margin,
+	}
+	d, _ := json.Marshal(info)
+	url := fmt.Sprintf("%s/api/v1/%d/transaction/app/energy", *node, *chain)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(d))
+	if err != nil {
+		return out, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	key := *apiKey
+	if key == "" {
+		key = os.Getenv("GOVM_API_KEY")
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return out, err
+	}
+	defer resp.Body.Close()
+	d, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return out, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return out, fmt.Errorf("error status:%s,%s", resp.Status, d)
+	}
+	err = json.Unmarshal(d, &out)
+	if err != nil {
+		return out, err
+	}
+	if out.Error != "" {
+		return out, fmt.Errorf("fail to run app,used:%d,%s", out.Used, out.Error)
+	}
+	return out, nil
+}
+
+func loadWallet() (wallet.TWallet, error) {
+	if *password == "" {
+		*password = os.Getenv("GOVM_PASSWORD")
+	}
+	if *password == "" {
+		fmt.Fprintln(os.Stderr, "WARNING: use the default password, set it by -password or GOVM_PASSWORD")
+		*password = wallet.DefaultPassword
+	}
+	if *from == "" {
+		return wallet.LoadWallet(*walletFile, *password)
+	}
+	ks, err := wallet.OpenKeyStore(*keyStore, *password)
+	if err != nil {
+		return wallet.TWallet{}, err
+	}
+	return ks.GetWallet(*from)
+}
+
+func main() {
+	flag.Parse()
+	w, err := loadWallet()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fail to load wallet:", err)
+		os.Exit(2)
+	}
+	if w.Address[0] == wallet.EAddrTypeIBS && wallet.GetDeadlineOfIBS(w.Address) < uint64(time.Now().Unix()*1000) {
+		fmt.Fprintln(os.Stderr, "the wallet is expired:", w.Tag)
+		os.Exit(2)
+	}
+	user := core.Address{}
+	runtime.Decode(w.Address, &user)
+	trans, err := buildTransaction(user)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fail to build transaction:", err)
+		os.Exit(3)
+	}
+	if *estimate || *autoEnergy {
+		est, err := estimateEnergy(user)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "fail to estimate energy:", err)
+			os.Exit(6)
+		}
+		if *estimate {
+			fmt.Printf("energy used:%d\nmin energy:%d\nenergy:%d(margin %d%%)\n",
+				est.Used, est.MinEnergy, est.Energy, est.Margin)
+			return
+		}
+		if est.Energy > *energy {
+			trans.Energy = est.Energy
+		}
+	}
+
+	td := trans.GetSignData()
+	sign := wallet.Sign(w.Key, td)
+	if len(w.SignPrefix) > 0 {
+		s := make([]byte, len(w.SignPrefix))
+		copy(s, w.SignPrefix)
+		sign = append(s, sign...)
+	}
+	trans.SetSign(sign)
+	data := trans.Output()
+	if core.DecodeTrans(data) == nil {
+		fmt.Fprintln(os.Stderr, "fail to check the transaction")
+		os.Exit(4)
+	}
+
+	var out []byte
+	switch *format {
+	case "raw":
+		out = data
+	default:
+		out = []byte(hex.EncodeToString(data) + "\n")
+	}
+	if *output != "" {
+		err = ioutil.WriteFile(*output, out, 0644)
+	} else {
+		_, err = os.Stdout.Write(out)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fail to write transaction:", err)
+		os.Exit(5)
+	}
+	fmt.Fprintf(os.Stderr, "chain:%d,user:%x,ops:%s,energy:%d,key:%x\n",
+		trans.Chain, trans.User, *ops, trans.Energy, trans.Key)
+}
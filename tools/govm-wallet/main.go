@@ -0,0 +1,196 @@
+// Command govm-wallet manage the accounts of keystore(conf/keystore.json).
+// the node sign transaction by the account if the request has "from"(name or address).
+//
+//	govm-wallet init [mnemonic words]         init the seed, create new mnemonic if words is empty
+//	govm-wallet mnemonic                      show the mnemonic to backup
+//	govm-wallet new [name]                    derive new account
+//	govm-wallet list                          list accounts
+//	govm-wallet rename <name> <new name>      rename the account
+//	govm-wallet delete <name>                 delete the account
+//	govm-wallet import <name> <wallet file> [wallet password]
+//	govm-wallet import-key <name> <private key(hex)>
+//	govm-wallet export <name> <wallet file> [wallet password]
+//	govm-wallet passwd <new password> [wallet files]  change the password of keystore and wallet files
+//
+// the password is read from the environment variable GOVM_PASSWORD if -password is empty
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/govm-net/govm/wallet"
+)
+
+var (
+	keyStoreFile = flag.String("keystore", "./conf/keystore.json", "keystore file")
+	password     = flag.String("password", "", "password of keystore, default $GOVM_PASSWORD")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: govm-wallet [flags] <init|mnemonic|new|list|rename|delete|import|import-key|export|passwd> [args]")
+	flag.PrintDefaults()
+	os.Exit(1)
+}
+
+func printAccount(acc wallet.TAccount) {
+	fmt.Printf("%-16s %s", acc.Name, acc.AddressStr)
+	if acc.Path != "" {
+		fmt.Printf(" %s", acc.Path)
+	} else {
+		fmt.Printf(" imported")
+	}
+	if acc.Tag != "" {
+		fmt.Printf(" %s", acc.Tag)
+	}
+	fmt.Println("")
+}
+
+func run(ks *wallet.KeyStore, cmd string, args []string) error {
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+	switch cmd {
+	case "init":
+		m, err := ks.InitMnemonic(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		fmt.Println("mnemonic(write it down and keep it safe):")
+		fmt.Println(m)
+	case "mnemonic":
+		m, err := ks.Mnemonic()
+		if err != nil {
+			return err
+		}
+		fmt.Println(m)
+	case "new":
+		acc, err := ks.NewAccount(arg(0))
+		if err != nil {
+			return err
+		}
+		printAccount(acc)
+	case "list":
+		for _, acc := range ks.List() {
+			printAccount(acc)
+		}
+	case "rename":
+		if len(args) != 2 {
+			usage()
+		}
+		return ks.Rename(args[0], args[1])
+	case "delete":
+		if len(args) != 1 {
+			usage()
+		}
+		return ks.Delete(args[0])
+	case "import":
+		if len(args) < 2 {
+			usage()
+		}
+		pwd := *password
+		if len(args) > 2 {
+			pwd = args[2]
+		}
+		w, err := wallet.LoadWallet(args[1], pwd)
+		if err != nil {
+			return err
+		}
+		acc, err := ks.ImportKey(args[0], w.Key, w.Address, w.SignPrefix)
+		if err != nil {
+			return err
+		}
+		printAccount(acc)
+	case "import-key":
+		if len(args) != 2 {
+			usage()
+		}
+		k, err := hex.DecodeString(args[1])
+		if err != nil {
+			return fmt.Errorf("error private key,must hex string:%s", err)
+		}
+		acc, err := ks.ImportKey(args[0], k, nil, nil)
+		if err != nil {
+			return err
+		}
+		printAccount(acc)
+	case "export":
+		if len(args) < 2 {
+			usage()
+		}
+		pwd := *password
+		if len(args) > 2 {
+			pwd = args[2]
+		}
+		if _, err := os.Stat(args[1]); err == nil {
+			return fmt.Errorf("the file is exist:%s", args[1])
+		}
+		w, err := ks.GetWallet(args[0])
+		if err != nil {
+			return err
+		}
+		return wallet.SaveWallet(args[1], pwd, w.Address, w.Key, w.SignPrefix)
+	case "passwd":
+		if len(args) < 1 {
+			usage()
+		}
+		if args[0] == wallet.DefaultPassword {
+			return fmt.Errorf("can not use the default password")
+		}
+		for _, fn := range args[1:] {
+			w, err := wallet.LoadWallet(fn, *password)
+			if err != nil {
+				return fmt.Errorf("fail to load wallet file %s:%s", fn, err)
+			}
+			err = wallet.SaveWallet(fn, args[0], w.Address, w.Key, w.SignPrefix)
+			if err != nil {
+				return err
+			}
+			fmt.Println("change the password of", fn)
+		}
+		err := ks.ChangePassword(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println("change the password of", *keyStoreFile)
+	default:
+		usage()
+	}
+	return nil
+}
+
+func getPassword() string {
+	if *password != "" {
+		return *password
+	}
+	if pwd := os.Getenv("GOVM_PASSWORD"); pwd != "" {
+		return pwd
+	}
+	fmt.Fprintln(os.Stderr, "WARNING: use the default password, set it by -password or GOVM_PASSWORD")
+	return wallet.DefaultPassword
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+	}
+	*password = getPassword()
+	ks, err := wallet.OpenKeyStore(*keyStoreFile, *password)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fail to open keystore:", err)
+		os.Exit(2)
+	}
+	err = run(ks, flag.Arg(0), flag.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(3)
+	}
+}
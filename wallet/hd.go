@@ -0,0 +1,102 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+const (
+	// HardenedKeyStart the first index of hardened child key
+	HardenedKeyStart = uint32(0x80000000)
+	// HDPathFormat the path of account(BIP-0044),the param is the index of account
+	HDPathFormat = "m/44'/2019'/%d'/0/0"
+)
+
+// HDKey the extended private key(BIP-0032)
+type HDKey struct {
+	Key       []byte
+	ChainCode []byte
+}
+
+// NewMasterKey create master key from seed
+func NewMasterKey(seed []byte) (*HDKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errors.New("error length of seed")
+	}
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	l := mac.Sum(nil)
+	k := new(big.Int).SetBytes(l[:32])
+	if k.Sign() == 0 || k.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("invalid seed")
+	}
+	return &HDKey{Key: l[:32], ChainCode: l[32:]}, nil
+}
+
+// Child derive the child key, index >= HardenedKeyStart is hardened key
+func (k *HDKey) Child(index uint32) (*HDKey, error) {
+	var data []byte
+	if index >= HardenedKeyStart {
+		data = append([]byte{0}, k.Key...)
+	} else {
+		data = GetPublicKey(k.Key)
+	}
+	var id [4]byte
+	binary.BigEndian.PutUint32(id[:], index)
+	data = append(data, id[:]...)
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(data)
+	l := mac.Sum(nil)
+	n := btcec.S256().N
+	il := new(big.Int).SetBytes(l[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, errors.New("invalid child key")
+	}
+	il.Add(il, new(big.Int).SetBytes(k.Key))
+	il.Mod(il, n)
+	if il.Sign() == 0 {
+		return nil, errors.New("invalid child key")
+	}
+	out := &HDKey{Key: make([]byte, privateKeyLen), ChainCode: l[32:]}
+	b := il.Bytes()
+	copy(out.Key[privateKeyLen-len(b):], b)
+	return out, nil
+}
+
+// Derive derive the key by path,such as m/44'/2019'/0'/0/0
+func (k *HDKey) Derive(path string) (*HDKey, error) {
+	items := strings.Split(path, "/")
+	if len(items) == 0 || items[0] != "m" {
+		return nil, fmt.Errorf("error path:%s", path)
+	}
+	out := k
+	for _, it := range items[1:] {
+		var hardened bool
+		if strings.HasSuffix(it, "'") || strings.HasSuffix(it, "H") {
+			hardened = true
+			it = it[:len(it)-1]
+		}
+		id, err := strconv.ParseUint(it, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("error path:%s", path)
+		}
+		index := uint32(id)
+		if hardened {
+			index += HardenedKeyStart
+		}
+		out, err = out.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
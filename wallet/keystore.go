@@ -0,0 +1,385 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/govm-net/govm/encrypt"
+)
+
+// TAccount the account of keystore, Key is encrypted
+type TAccount struct {
+	Name       string `json:"name,omitempty"`
+	AddressStr string `json:"address_str,omitempty"`
+	Address    []byte `json:"address,omitempty"`
+	Key        []byte `json:"key,omitempty"`
+	SignPrefix []byte `json:"sign_prefix,omitempty"`
+	// Path the HD path of the account, empty if it is imported
+	Path string `json:"path,omitempty"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+type keyStoreData struct {
+	Version   int        `json:"version"`
+	Mnemonic  []byte     `json:"mnemonic,omitempty"`
+	NextIndex uint32     `json:"next_index,omitempty"`
+	Accounts  []TAccount `json:"accounts,omitempty"`
+}
+
+// KeyStore the wallet with multi named accounts,
+// the accounts are derived from the seed(mnemonic) or imported
+type KeyStore struct {
+	mu       sync.Mutex
+	file     string
+	password string
+	data     keyStoreData
+}
+
+// the version of keystore, version 1 is encrypted by encrypt.AesEncrypt,
+// version 2 is encrypted by encrypt.Seal(scrypt+AES-GCM)
+const keyStoreVersion = 2
+
+// OpenKeyStore open the keystore file, create empty keystore if the file not exist
+func OpenKeyStore(file, password string) (*KeyStore, error) {
+	ks := &KeyStore{file: file, password: password}
+	ks.data.Version = keyStoreVersion
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return ks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &ks.data)
+	if err != nil {
+		return nil, err
+	}
+	// check password
+	switch {
+	case len(ks.data.Mnemonic) > 0:
+		_, err = ks.decrypt(ks.data.Mnemonic)
+	case len(ks.data.Accounts) > 0:
+		_, err = ks.decrypt(ks.data.Accounts[0].Key)
+	}
+	if err != nil {
+		return nil, errors.New("error password of keystore")
+	}
+	if ks.data.Version < keyStoreVersion {
+		// upgrade the old keystore in place
+		err = ks.reencrypt(password)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+func (ks *KeyStore) encrypt(in []byte) ([]byte, error) {
+	return encrypt.Seal(ks.password, in)
+}
+
+func (ks *KeyStore) decrypt(in []byte) ([]byte, error) {
+	if ks.data.Version < keyStoreVersion {
+		aesEnc := encrypt.AesEncrypt{}
+		aesEnc.Key = ks.password
+		return aesEnc.Decrypt(in)
+	}
+	return encrypt.Open(ks.password, in)
+}
+
+// reencrypt decrypt all keys and encrypt them with the password and the new version,
+// must be called with lock
+func (ks *KeyStore) reencrypt(password string) error {
+	old := ks.data
+	var err error
+	data := old
+	data.Version = keyStoreVersion
+	data.Accounts = make([]TAccount, len(old.Accounts))
+	copy(data.Accounts, old.Accounts)
+	conv := func(in []byte) []byte {
+		if err != nil || len(in) == 0 {
+			return in
+		}
+		var d []byte
+		d, err = ks.decrypt(in)
+		if err != nil {
+			return nil
+		}
+		d, err = encrypt.Seal(password, d)
+		return d
+	}
+	data.Mnemonic = conv(old.Mnemonic)
+	for i := range data.Accounts {
+		data.Accounts[i].Key = conv(data.Accounts[i].Key)
+	}
+	if err != nil {
+		return err
+	}
+	oldPwd := ks.password
+	ks.data = data
+	ks.password = password
+	err = ks.save()
+	if err != nil {
+		ks.data = old
+		ks.password = oldPwd
+	}
+	return err
+}
+
+// ChangePassword change the password of keystore
+func (ks *KeyStore) ChangePassword(password string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if password == "" {
+		return errors.New("empty password")
+	}
+	return ks.reencrypt(password)
+}
+
+// save must be called with lock
+func (ks *KeyStore) save() error {
+	data, err := json.MarshalIndent(ks.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := ks.file + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, ks.file)
+}
+
+// find the account by name or address(hex), must be called with lock
+func (ks *KeyStore) find(name string) int {
+	addr, _ := hex.DecodeString(name)
+	for i, it := range ks.data.Accounts {
+		if it.Name == name {
+			return i
+		}
+		if len(addr) == AddressLength && bytes.Compare(addr, it.Address) == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (ks *KeyStore) checkName(name string) error {
+	if name == "" || len(name) > 64 {
+		return errors.New("error name of account")
+	}
+	if _, err := hex.DecodeString(name); err == nil && len(name) == 2*AddressLength {
+		return errors.New("the name of account can not be address")
+	}
+	if ks.find(name) >= 0 {
+		return fmt.Errorf("the account is exist:%s", name)
+	}
+	return nil
+}
+
+// HasMnemonic return true if the seed of keystore is initialized
+func (ks *KeyStore) HasMnemonic() bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return len(ks.data.Mnemonic) > 0
+}
+
+// InitMnemonic init the seed of keystore, create new mnemonic if it is empty, return the mnemonic
+func (ks *KeyStore) InitMnemonic(mnemonic string) (string, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if len(ks.data.Mnemonic) > 0 {
+		return "", errors.New("the mnemonic is exist")
+	}
+	if mnemonic == "" {
+		entropy, err := NewEntropy(128)
+		if err != nil {
+			return "", err
+		}
+		mnemonic, _ = NewMnemonic(entropy)
+	}
+	_, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return "", err
+	}
+	d, err := ks.encrypt([]byte(mnemonic))
+	if err != nil {
+		return "", err
+	}
+	ks.data.Mnemonic = d
+	ks.data.NextIndex = 0
+	return mnemonic, ks.save()
+}
+
+// Mnemonic return the mnemonic of keystore, it is used to backup
+func (ks *KeyStore) Mnemonic() (string, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if len(ks.data.Mnemonic) == 0 {
+		return "", errors.New("not found the mnemonic")
+	}
+	d, err := ks.decrypt(ks.data.Mnemonic)
+	return string(d), err
+}
+
+func (ks *KeyStore) add(acc TAccount, privKey []byte) (TAccount, error) {
+	if acc.Name == "" {
+		acc.Name = fmt.Sprintf("account%d", len(ks.data.Accounts))
+	}
+	if err := ks.checkName(acc.Name); err != nil {
+		return TAccount{}, err
+	}
+	if ks.find(hex.EncodeToString(acc.Address)) >= 0 {
+		return TAccount{}, fmt.Errorf("the address is exist:%x", acc.Address)
+	}
+	k, err := ks.encrypt(privKey)
+	if err != nil {
+		return TAccount{}, err
+	}
+	acc.Key = k
+	acc.AddressStr = hex.EncodeToString(acc.Address)
+	if acc.Address[0] == EAddrTypeIBS {
+		t := GetDeadlineOfIBS(acc.Address)
+		acc.Tag = time.Unix(int64(t/1000), 0).Format(time.RFC3339)
+	}
+	ks.data.Accounts = append(ks.data.Accounts, acc)
+	err = ks.save()
+	if err != nil {
+		ks.data.Accounts = ks.data.Accounts[:len(ks.data.Accounts)-1]
+		return TAccount{}, err
+	}
+	acc.Key = nil
+	return acc, nil
+}
+
+// NewAccount derive new account from the seed
+func (ks *KeyStore) NewAccount(name string) (TAccount, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if len(ks.data.Mnemonic) == 0 {
+		return TAccount{}, errors.New("not found the mnemonic, init it first")
+	}
+	m, err := ks.decrypt(ks.data.Mnemonic)
+	if err != nil {
+		return TAccount{}, err
+	}
+	master, err := NewMasterKey(MnemonicToSeed(string(m), ""))
+	if err != nil {
+		return TAccount{}, err
+	}
+	for {
+		index := ks.data.NextIndex
+		ks.data.NextIndex++
+		path := fmt.Sprintf(HDPathFormat, index)
+		k, err := master.Derive(path)
+		if err != nil {
+			// the key is invalid(very rare), try next index
+			continue
+		}
+		acc := TAccount{Name: name, Path: path}
+		acc.Address = PublicKeyToAddress(GetPublicKey(k.Key), EAddrTypeDefault)
+		acc, err = ks.add(acc, k.Key)
+		if err != nil {
+			ks.data.NextIndex--
+		}
+		return acc, err
+	}
+}
+
+// ImportKey import the private key, the address is created by the key if it is empty
+func (ks *KeyStore) ImportKey(name string, privKey, address, signPrefix []byte) (TAccount, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if len(privKey) != privateKeyLen {
+		return TAccount{}, errors.New("error length of private key")
+	}
+	if len(address) == 0 {
+		address = PublicKeyToAddress(GetPublicKey(privKey), EAddrTypeDefault)
+	}
+	if len(address) != AddressLength {
+		return TAccount{}, errors.New("error length of address")
+	}
+	acc := TAccount{Name: name, Address: address, SignPrefix: signPrefix}
+	return ks.add(acc, privKey)
+}
+
+// GetWallet get the account by name or address(hex), the Key of TWallet is the private key
+func (ks *KeyStore) GetWallet(name string) (TWallet, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	var out TWallet
+	i := ks.find(name)
+	if i < 0 {
+		return out, fmt.Errorf("not found the account:%s", name)
+	}
+	acc := ks.data.Accounts[i]
+	k, err := ks.decrypt(acc.Key)
+	if err != nil {
+		return out, err
+	}
+	out.Tag = acc.Tag
+	out.AddressStr = acc.AddressStr
+	out.Address = acc.Address
+	out.Key = k
+	out.SignPrefix = acc.SignPrefix
+	return out, nil
+}
+
+// List list the accounts of keystore, the Key is removed
+func (ks *KeyStore) List() []TAccount {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	out := make([]TAccount, len(ks.data.Accounts))
+	copy(out, ks.data.Accounts)
+	for i := range out {
+		out[i].Key = nil
+	}
+	return out
+}
+
+// Rename rename the account
+func (ks *KeyStore) Rename(name, newName string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	i := ks.find(name)
+	if i < 0 {
+		return fmt.Errorf("not found the account:%s", name)
+	}
+	if err := ks.checkName(newName); err != nil {
+		return err
+	}
+	old := ks.data.Accounts[i].Name
+	ks.data.Accounts[i].Name = newName
+	err := ks.save()
+	if err != nil {
+		ks.data.Accounts[i].Name = old
+	}
+	return err
+}
+
+// Delete delete the account,the derived account can be recovered by mnemonic
+func (ks *KeyStore) Delete(name string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	i := ks.find(name)
+	if i < 0 {
+		return fmt.Errorf("not found the account:%s", name)
+	}
+	old := ks.data.Accounts
+	list := make([]TAccount, 0, len(old)-1)
+	list = append(list, old[:i]...)
+	list = append(list, old[i+1:]...)
+	ks.data.Accounts = list
+	err := ks.save()
+	if err != nil {
+		ks.data.Accounts = old
+	}
+	return err
+}
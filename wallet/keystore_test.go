@@ -0,0 +1,178 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/govm-net/govm/encrypt"
+)
+
+func TestMnemonic(t *testing.T) {
+	// the test vectors of BIP-0039
+	vectors := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"},
+		{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow", ""},
+		{"80808080808080808080808080808080",
+			"letter advice cage absurd amount doctor acoustic avoid letter advice cage above", ""},
+		{"ffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong", ""},
+	}
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		m, err := NewMnemonic(entropy)
+		if err != nil || m != v.mnemonic {
+			t.Error("error mnemonic:", m, err)
+		}
+		e, err := MnemonicToEntropy(m)
+		if err != nil || bytes.Compare(e, entropy) != 0 {
+			t.Errorf("error entropy:%x,%s", e, err)
+		}
+		if v.seed != "" {
+			seed := MnemonicToSeed(m, "TREZOR")
+			if hex.EncodeToString(seed) != v.seed {
+				t.Errorf("error seed:%x", seed)
+			}
+		}
+	}
+	_, err := MnemonicToEntropy("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon")
+	if err == nil {
+		t.Error("hope checksum error")
+	}
+}
+
+func TestHDKey(t *testing.T) {
+	// the test vector 1 of BIP-0032
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	m, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(m.Key) != "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35" {
+		t.Errorf("error master key:%x", m.Key)
+	}
+	k, err := m.Derive("m/0'/1/2'/2/1000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(k.Key) != "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8" {
+		t.Errorf("error child key:%x", k.Key)
+	}
+}
+
+func TestKeyStore(t *testing.T) {
+	fn := "keystore_test.json"
+	pwd := "aaaaa"
+	defer os.Remove(fn)
+	ks, err := OpenKeyStore(fn, pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ks.NewAccount("a1")
+	if err == nil {
+		t.Error("hope error without mnemonic")
+	}
+	m, err := ks.InitMnemonic("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1, err := ks.NewAccount("a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ks.NewAccount("a1")
+	if err == nil {
+		t.Error("hope error of same name")
+	}
+	priv := NewPrivateKey()
+	a2, err := ks.ImportKey("", priv, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ks.Rename(a2.Name, "a2")
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = OpenKeyStore(fn, "bbbbb")
+	if err == nil {
+		t.Error("hope error password")
+	}
+	ks2, err := OpenKeyStore(fn, pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m2, _ := ks2.Mnemonic(); m2 != m {
+		t.Error("different mnemonic")
+	}
+	w, err := ks2.GetWallet("a2")
+	if err != nil || bytes.Compare(w.Key, priv) != 0 {
+		t.Error("error key of a2", err)
+	}
+	w, err = ks2.GetWallet(a1.AddressStr)
+	if err != nil || bytes.Compare(w.Address, a1.Address) != 0 {
+		t.Error("error address of a1", err)
+	}
+	if len(ks2.List()) != 2 {
+		t.Error("error number of accounts")
+	}
+
+	err = ks2.ChangePassword("ccccc")
+	if err != nil {
+		t.Error(err)
+	}
+	if _, err = OpenKeyStore(fn, pwd); err == nil {
+		t.Error("hope error of old password")
+	}
+	ks2.ChangePassword(pwd)
+
+	// recover the derived account by mnemonic
+	ks2.Delete("a1")
+	os.Remove(fn)
+	ks3, _ := OpenKeyStore(fn, pwd)
+	ks3.InitMnemonic(m)
+	a3, err := ks3.NewAccount("")
+	if err != nil || a3.AddressStr != a1.AddressStr || a3.Path != a1.Path {
+		t.Error("fail to recover account", a3, err)
+	}
+}
+
+func TestMigrateKeyStore(t *testing.T) {
+	fn := "keystore_old.json"
+	pwd := "aaaaa"
+	defer os.Remove(fn)
+	priv := NewPrivateKey()
+	aesEnc := encrypt.AesEncrypt{Key: pwd}
+	k, _ := aesEnc.Encrypt(priv)
+	// the keystore of version 1
+	old := keyStoreData{Version: 1}
+	old.Accounts = append(old.Accounts, TAccount{Name: "a1", Address: PublicKeyToAddress(GetPublicKey(priv), EAddrTypeDefault), Key: k})
+	d, _ := json.Marshal(old)
+	ioutil.WriteFile(fn, d, 0600)
+
+	ks, err := OpenKeyStore(fn, pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ks.data.Version != keyStoreVersion {
+		t.Error("not upgraded")
+	}
+	ks, err = OpenKeyStore(fn, pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := ks.GetWallet("a1")
+	if err != nil || bytes.Compare(w.Key, priv) != 0 {
+		t.Error("error key after upgrade", err)
+	}
+}
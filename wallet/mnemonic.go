@@ -0,0 +1,95 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	wordList  = strings.Fields(englishWords)
+	wordIndex = make(map[string]int)
+)
+
+func init() {
+	for i, w := range wordList {
+		wordIndex[w] = i
+	}
+}
+
+// NewEntropy create random entropy for mnemonic,bits:128~256 and multiple of 32
+func NewEntropy(bits int) ([]byte, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return nil, errors.New("error bits of entropy")
+	}
+	out := make([]byte, bits/8)
+	_, err := rand.Read(out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NewMnemonic create mnemonic(BIP-0039) from the entropy
+func NewMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", errors.New("error length of entropy")
+	}
+	csBits := uint(bits / 32)
+	h := sha256.Sum256(entropy)
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, csBits)
+	data.Or(data, big.NewInt(int64(h[0]>>(8-csBits))))
+
+	num := (bits + int(csBits)) / 11
+	words := make([]string, num)
+	mask := big.NewInt(2047)
+	for i := num - 1; i >= 0; i-- {
+		id := new(big.Int).And(data, mask)
+		words[i] = wordList[id.Int64()]
+		data.Rsh(data, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy check the mnemonic and return the entropy
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	num := len(words)
+	if num < 12 || num > 24 || num%3 != 0 {
+		return nil, errors.New("error number of mnemonic words")
+	}
+	data := new(big.Int)
+	for _, w := range words {
+		id, ok := wordIndex[w]
+		if !ok {
+			return nil, errors.New("unknown word of mnemonic:" + w)
+		}
+		data.Lsh(data, 11)
+		data.Or(data, big.NewInt(int64(id)))
+	}
+	csBits := uint(num * 11 / 33)
+	cs := new(big.Int).And(data, big.NewInt(1<<csBits-1))
+	data.Rsh(data, csBits)
+
+	entropy := make([]byte, num*11/33*4)
+	b := data.Bytes()
+	copy(entropy[len(entropy)-len(b):], b)
+	h := sha256.Sum256(entropy)
+	if cs.Int64() != int64(h[0]>>(8-csBits)) {
+		return nil, errors.New("error checksum of mnemonic")
+	}
+	return entropy, nil
+}
+
+// MnemonicToSeed create the seed of HD wallet, the mnemonic must be checked
+func MnemonicToSeed(mnemonic, passphrase string) []byte {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
@@ -3,6 +3,7 @@ package wallet
 import (
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"github.com/govm-net/govm/encrypt"
 	"io/ioutil"
 	"log"
@@ -10,8 +11,17 @@ import (
 	"time"
 )
 
+const (
+	// WalletVersion the version of wallet file, the key is encrypted by encrypt.Seal(scrypt+AES-GCM),
+	// version 0 is encrypted by encrypt.AesEncrypt
+	WalletVersion = 1
+	// DefaultPassword the default password of old version, it is not safe
+	DefaultPassword = "govm_pwd@2019"
+)
+
 // TWallet 钱包存储的结构体
 type TWallet struct {
+	Version    int    `json:"version,omitempty"`
 	Tag        string `json:"tag,omitempty"`
 	AddressStr string `json:"address_str,omitempty"`
 	Address    []byte `json:"address,omitempty"`
@@ -34,9 +44,7 @@ func LoadWallet(file, password string) (TWallet, error) {
 		return info, err
 	}
 
-	aesEnc := encrypt.AesEncrypt{}
-	aesEnc.Key = password
-	privKey, err := aesEnc.Decrypt(info.Key)
+	privKey, err := decryptKey(info.Version, password, info.Key)
 	if err != nil {
 		log.Println("fail to decrypt privateKey.", err)
 		return info, err
@@ -48,15 +56,13 @@ func LoadWallet(file, password string) (TWallet, error) {
 
 // SaveWallet 将私钥信息和地址保存到文件中
 func SaveWallet(file, passwd string, addr, privKey, prefix []byte) error {
-	aesEnc := encrypt.AesEncrypt{}
-	aesEnc.Key = passwd
-
-	arrEncrypt, err := aesEnc.Encrypt(privKey)
+	arrEncrypt, err := encrypt.Seal(passwd, privKey)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
 	info := TWallet{}
+	info.Version = WalletVersion
 	info.AddressStr = hex.EncodeToString(addr)
 	info.Address = addr
 	info.SignPrefix = prefix
@@ -67,12 +73,39 @@ func SaveWallet(file, passwd string, addr, privKey, prefix []byte) error {
 	}
 
 	sd, _ := json.Marshal(info)
-	f, err := os.Create(file)
+	tmp := file + ".tmp"
+	err = ioutil.WriteFile(tmp, sd, 0600)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	f.Write(sd)
-	f.Close()
-	return nil
+	return os.Rename(tmp, file)
+}
+
+func decryptKey(version int, password string, key []byte) ([]byte, error) {
+	switch version {
+	case 0:
+		aesEnc := encrypt.AesEncrypt{}
+		aesEnc.Key = password
+		return aesEnc.Decrypt(key)
+	case WalletVersion:
+		return encrypt.Open(password, key)
+	}
+	return nil, fmt.Errorf("unknown version of wallet:%d", version)
+}
+
+// MigrateWallet upgrade the wallet file to the new version in place, return true if it is upgraded
+func MigrateWallet(file, password string) (bool, error) {
+	w, err := LoadWallet(file, password)
+	if err != nil {
+		return false, err
+	}
+	if w.Version == WalletVersion {
+		return false, nil
+	}
+	err = SaveWallet(file, password, w.Address, w.Key, w.SignPrefix)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
@@ -2,10 +2,14 @@ package wallet
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"testing"
 	"time"
+
+	"github.com/govm-net/govm/encrypt"
 )
 
 func TestSaveWallet(t *testing.T) {
@@ -48,3 +52,33 @@ func TestSaveWallet(t *testing.T) {
 	fmt.Println("address:", w1.Tag, w1.AddressStr, address)
 
 }
+
+func TestMigrateWallet(t *testing.T) {
+	fn := "old.key"
+	pwd := "aaaaa"
+	defer os.Remove(fn)
+	priv := NewPrivateKey()
+	address := PublicKeyToAddress(GetPublicKey(priv), EAddrTypeDefault)
+	// the wallet file of version 0
+	aesEnc := encrypt.AesEncrypt{Key: pwd}
+	k, _ := aesEnc.Encrypt(priv)
+	d, _ := json.Marshal(TWallet{Address: address, Key: k})
+	ioutil.WriteFile(fn, d, 0600)
+
+	_, err := MigrateWallet(fn, "bbbbb")
+	if err == nil {
+		t.Error("hope error password")
+	}
+	ok, err := MigrateWallet(fn, pwd)
+	if !ok || err != nil {
+		t.Fatal("fail to migrate wallet,", err)
+	}
+	w, err := LoadWallet(fn, pwd)
+	if err != nil || w.Version != WalletVersion || bytes.Compare(w.Key, priv) != 0 {
+		t.Error("error wallet after migrate,", w.Version, err)
+	}
+	ok, _ = MigrateWallet(fn, pwd)
+	if ok {
+		t.Error("migrate again")
+	}
+}